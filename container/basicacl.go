@@ -0,0 +1,352 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"sort"
+	"strings"
+)
+
+// BasicACLOps are the operations a basic ACL covers, in the order they are explained.
+var BasicACLOps = []acl.Op{
+	acl.OpObjectGet,
+	acl.OpObjectHead,
+	acl.OpObjectPut,
+	acl.OpObjectDelete,
+	acl.OpObjectSearch,
+	acl.OpObjectRange,
+	acl.OpObjectHash,
+}
+
+// BasicACLRoles are the roles a basic ACL covers, in the order they are explained.
+var BasicACLRoles = []acl.Role{
+	acl.RoleOwner,
+	acl.RoleContainer,
+	acl.RoleInnerRing,
+	acl.RoleOthers,
+}
+
+var basicACLOpNames = map[acl.Op]string{
+	acl.OpObjectGet:    "get",
+	acl.OpObjectHead:   "head",
+	acl.OpObjectPut:    "put",
+	acl.OpObjectDelete: "delete",
+	acl.OpObjectSearch: "search",
+	acl.OpObjectRange:  "range",
+	acl.OpObjectHash:   "rangehash",
+}
+
+var basicACLRoleNames = map[acl.Role]string{
+	acl.RoleOwner:     "owner",
+	acl.RoleContainer: "container",
+	acl.RoleInnerRing: "innerRing",
+	acl.RoleOthers:    "others",
+}
+
+// fixedForNetwork reports whether the network decides the rule itself, so the bits for it are ignored.
+func fixedForNetwork(role acl.Role, op acl.Op) bool {
+	switch role {
+	case acl.RoleInnerRing:
+		return true
+	case acl.RoleContainer:
+		//container nodes always replicate
+		switch op {
+		case acl.OpObjectGet, acl.OpObjectHead, acl.OpObjectPut, acl.OpObjectSearch, acl.OpObjectHash:
+			return true
+		}
+	}
+	return false
+}
+
+// BasicACLBuilder builds a basic ACL one rule at a time. What the inner ring and the container's own nodes may do
+// for replication is fixed by the network, so rules for those are left as they are.
+type BasicACLBuilder struct {
+	allowed map[acl.Role]map[acl.Op]bool
+	bearer  map[acl.Op]bool
+	sticky  bool
+	final   bool
+	err     error
+}
+
+// NewBasicACL starts a basic ACL that allows nothing, is not sticky and can be extended by eACL.
+func NewBasicACL() *BasicACLBuilder {
+	b := &BasicACLBuilder{
+		allowed: make(map[acl.Role]map[acl.Op]bool),
+		bearer:  make(map[acl.Op]bool),
+	}
+	for _, role := range []acl.Role{acl.RoleOwner, acl.RoleContainer, acl.RoleOthers} {
+		b.allowed[role] = make(map[acl.Op]bool)
+	}
+	return b
+}
+
+// BasicACLFrom starts from an existing basic ACL, e.g. a preset, to change some of its rules.
+func BasicACLFrom(basic acl.Basic) *BasicACLBuilder {
+	b := NewBasicACL()
+	for role, ops := range b.allowed {
+		for _, op := range BasicACLOps {
+			if !fixedForNetwork(role, op) && basic.IsOpAllowed(op, role) {
+				ops[op] = true
+			}
+		}
+	}
+	for _, op := range BasicACLOps {
+		if basic.AllowedBearerRules(op) {
+			b.bearer[op] = true
+		}
+	}
+	b.sticky = basic.Sticky()
+	b.final = !basic.Extendable()
+	return b
+}
+
+func (b *BasicACLBuilder) set(role acl.Role, allow bool, ops []acl.Op) *BasicACLBuilder {
+	if len(ops) == 0 {
+		ops = BasicACLOps
+	}
+	rules, ok := b.allowed[role]
+	if !ok {
+		if b.err == nil {
+			b.err = fmt.Errorf("the rules for %s cannot be changed", role)
+		}
+		return b
+	}
+	for _, op := range ops {
+		if _, ok := basicACLOpNames[op]; !ok {
+			if b.err == nil {
+				b.err = fmt.Errorf("unknown operation %s", op)
+			}
+			continue
+		}
+		if fixedForNetwork(role, op) {
+			continue
+		}
+		rules[op] = allow
+	}
+	return b
+}
+
+// Allow lets role perform ops, or every operation if none are given.
+func (b *BasicACLBuilder) Allow(role acl.Role, ops ...acl.Op) *BasicACLBuilder {
+	return b.set(role, true, ops)
+}
+
+// Deny stops role performing ops, or any operation if none are given.
+func (b *BasicACLBuilder) Deny(role acl.Role, ops ...acl.Op) *BasicACLBuilder {
+	return b.set(role, false, ops)
+}
+
+// AllowBearer lets bearer tokens grant ops, or every operation if none are given.
+func (b *BasicACLBuilder) AllowBearer(ops ...acl.Op) *BasicACLBuilder {
+	if len(ops) == 0 {
+		ops = BasicACLOps
+	}
+	for _, op := range ops {
+		b.bearer[op] = true
+	}
+	return b
+}
+
+// DenyBearer stops bearer tokens granting ops, or any operation if none are given.
+func (b *BasicACLBuilder) DenyBearer(ops ...acl.Op) *BasicACLBuilder {
+	if len(ops) == 0 {
+		ops = BasicACLOps
+	}
+	for _, op := range ops {
+		delete(b.bearer, op)
+	}
+	return b
+}
+
+// Sticky sets whether an object can only be deleted by whoever put it, even when others are allowed to delete.
+func (b *BasicACLBuilder) Sticky(sticky bool) *BasicACLBuilder {
+	b.sticky = sticky
+	return b
+}
+
+// Final sets whether the basic ACL is final. A final basic ACL cannot be extended by an eACL.
+func (b *BasicACLBuilder) Final(final bool) *BasicACLBuilder {
+	b.final = final
+	return b
+}
+
+// Extendable sets whether an eACL can restrict the basic ACL further, the opposite of Final.
+func (b *BasicACLBuilder) Extendable(extendable bool) *BasicACLBuilder {
+	b.final = !extendable
+	return b
+}
+
+func (b *BasicACLBuilder) Build() (acl.Basic, error) {
+	var basic acl.Basic
+	if b.err != nil {
+		return basic, b.err
+	}
+	for role, ops := range b.allowed {
+		for op, allowed := range ops {
+			if allowed {
+				basic.AllowOp(op, role)
+			}
+		}
+	}
+	for op := range b.bearer {
+		basic.AllowBearerRules(op)
+	}
+	if b.sticky {
+		basic.MakeSticky()
+	}
+	if b.final {
+		basic.DisableExtension()
+	}
+	return basic, nil
+}
+
+// the network presets, then ours
+var basicACLPresets = map[string]acl.Basic{
+	acl.NamePrivate:              acl.Private,
+	acl.NamePrivateExtended:      acl.PrivateExtended,
+	acl.NamePublicRO:             acl.PublicRO,
+	acl.NamePublicROExtended:     acl.PublicROExtended,
+	acl.NamePublicRW:             acl.PublicRW,
+	acl.NamePublicRWExtended:     acl.PublicRWExtended,
+	acl.NamePublicAppend:         acl.PublicAppend,
+	acl.NamePublicAppendExtended: acl.PublicAppendExtended,
+}
+
+const (
+	// NamePrivateShareable is private to the owner, but bearer tokens can share any operation.
+	NamePrivateShareable = "private-shareable"
+	// NamePublicReadShareable can be read by anyone, and bearer tokens can share any operation.
+	NamePublicReadShareable = "public-read-shareable"
+	// NameDropbox lets anyone put objects, but only the owner read or delete them.
+	NameDropbox = "dropbox"
+	// NameSharedWorkspace lets anyone read and write, but only the owner of an object delete it.
+	NameSharedWorkspace = "shared-workspace"
+)
+
+func init() {
+	readOps := []acl.Op{acl.OpObjectGet, acl.OpObjectHead, acl.OpObjectSearch, acl.OpObjectRange, acl.OpObjectHash}
+	presets := map[string]*BasicACLBuilder{
+		NamePrivateShareable:    NewBasicACL().Allow(acl.RoleOwner).AllowBearer(),
+		NamePublicReadShareable: NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers, readOps...).AllowBearer(),
+		NameDropbox:             NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers, acl.OpObjectPut).AllowBearer(),
+		NameSharedWorkspace:     NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers).AllowBearer().Sticky(true),
+	}
+	for name, b := range presets {
+		basic, err := b.Build()
+		if err != nil {
+			panic(err)
+		}
+		basicACLPresets[name] = basic
+	}
+}
+
+// BasicACLPresets lists the names BasicACLPreset knows.
+func BasicACLPresets() []string {
+	var names []string
+	for name := range basicACLPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BasicACLPreset returns the basic ACL called name. A hex value such as 0x1fbf8cff is accepted too.
+func BasicACLPreset(name string) (acl.Basic, error) {
+	if basic, ok := basicACLPresets[name]; ok {
+		return basic, nil
+	}
+	var basic acl.Basic
+	if err := basic.DecodeString(name); err != nil {
+		return basic, errors.New("unknown basic ACL " + name)
+	}
+	return basic, nil
+}
+
+// BasicACLRole is a row of BasicACLExplanation: whether Role may perform each of the explained operations.
+type BasicACLRole struct {
+	Role    string `json:"role"`
+	Allowed []bool `json:"allowed"`
+	//Fixed marks operations the network decides for this role, whatever the bits say
+	Fixed []bool `json:"fixed"`
+}
+
+// BasicACLExplanation is a basic ACL laid out for people to read.
+type BasicACLExplanation struct {
+	Bits       uint32         `json:"bits"`
+	Hex        string         `json:"hex"`
+	Preset     string         `json:"preset,omitempty"`
+	Operations []string       `json:"operations"`
+	Roles      []BasicACLRole `json:"roles"`
+	//Bearer is whether a bearer token may grant each operation
+	Bearer     []bool `json:"bearer"`
+	Sticky     bool   `json:"sticky"`
+	Final      bool   `json:"final"`
+	Extendable bool   `json:"extendable"`
+}
+
+// ExplainBasicACL lays a basic ACL out as a matrix of roles and operations with its flags.
+func ExplainBasicACL(bits uint32) BasicACLExplanation {
+	basic := acl.Basic(bits)
+	explanation := BasicACLExplanation{
+		Bits:       bits,
+		Hex:        fmt.Sprintf("0x%08x", bits),
+		Sticky:     basic.Sticky(),
+		Final:      !basic.Extendable(),
+		Extendable: basic.Extendable(),
+	}
+	for _, name := range BasicACLPresets() {
+		if basicACLPresets[name] == basic {
+			explanation.Preset = name
+			break
+		}
+	}
+	for _, op := range BasicACLOps {
+		explanation.Operations = append(explanation.Operations, basicACLOpNames[op])
+		explanation.Bearer = append(explanation.Bearer, basic.AllowedBearerRules(op))
+	}
+	for _, role := range BasicACLRoles {
+		row := BasicACLRole{Role: basicACLRoleNames[role]}
+		for _, op := range BasicACLOps {
+			row.Allowed = append(row.Allowed, basic.IsOpAllowed(op, role))
+			row.Fixed = append(row.Fixed, fixedForNetwork(role, op))
+		}
+		explanation.Roles = append(explanation.Roles, row)
+	}
+	return explanation
+}
+
+// String draws the explanation as a text table for the terminal.
+func (e BasicACLExplanation) String() string {
+	var b strings.Builder
+	title := e.Hex
+	if e.Preset != "" {
+		title += " (" + e.Preset + ")"
+	}
+	b.WriteString(title + "\n")
+	fmt.Fprintf(&b, "%-10s", "")
+	for _, op := range e.Operations {
+		fmt.Fprintf(&b, " %-9s", op)
+	}
+	b.WriteString("\n")
+	cell := func(allowed bool) string {
+		if allowed {
+			return "yes"
+		}
+		return "-"
+	}
+	for _, row := range e.Roles {
+		fmt.Fprintf(&b, "%-10s", row.Role)
+		for _, allowed := range row.Allowed {
+			fmt.Fprintf(&b, " %-9s", cell(allowed))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%-10s", "bearer")
+	for _, allowed := range e.Bearer {
+		fmt.Fprintf(&b, " %-9s", cell(allowed))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "sticky: %s, extendable: %s\n", cell(e.Sticky), cell(e.Extendable))
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package container
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+)
+
+func TestBasicACLBuilderMatchesPresets(t *testing.T) {
+	readOps := []acl.Op{acl.OpObjectGet, acl.OpObjectHead, acl.OpObjectSearch, acl.OpObjectRange, acl.OpObjectHash}
+	cases := []struct {
+		name    string
+		builder *BasicACLBuilder
+		want    acl.Basic
+	}{
+		{"public read write extended", NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers).AllowBearer(), acl.PublicRWExtended},
+		{"public read write", NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers).AllowBearer().Final(true), acl.PublicRW},
+		{"public read extended", NewBasicACL().Allow(acl.RoleOwner).Allow(acl.RoleOthers, readOps...).AllowBearer(readOps...), acl.PublicROExtended},
+		{"private", NewBasicACL().Allow(acl.RoleOwner).Final(true), acl.Private},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.builder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			//the bits for rules the network fixes may differ, what they mean may not
+			if !sameMeaning(ExplainBasicACL(got.Bits()), ExplainBasicACL(c.want.Bits())) {
+				t.Errorf("built\n%s\nexpected\n%s", ExplainBasicACL(got.Bits()), ExplainBasicACL(c.want.Bits()))
+			}
+		})
+	}
+}
+
+func sameMeaning(a, b BasicACLExplanation) bool {
+	a.Bits, a.Hex, a.Preset = 0, "", ""
+	b.Bits, b.Hex, b.Preset = 0, "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+func TestBasicACLFromRoundTrip(t *testing.T) {
+	for _, name := range BasicACLPresets() {
+		preset, err := BasicACLPreset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rebuilt, err := BasicACLFrom(preset).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameMeaning(ExplainBasicACL(rebuilt.Bits()), ExplainBasicACL(preset.Bits())) {
+			t.Errorf("%s changed when rebuilt\n%s\n%s", name, ExplainBasicACL(rebuilt.Bits()), ExplainBasicACL(preset.Bits()))
+		}
+	}
+}
+
+func TestBasicACLBuilderChanges(t *testing.T) {
+	basic, err := BasicACLFrom(acl.PublicRWExtended).Deny(acl.RoleOthers, acl.OpObjectDelete).DenyBearer(acl.OpObjectPut).Sticky(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if basic.IsOpAllowed(acl.OpObjectDelete, acl.RoleOthers) || !basic.IsOpAllowed(acl.OpObjectPut, acl.RoleOthers) {
+		t.Error("only delete should have been denied to others")
+	}
+	if basic.AllowedBearerRules(acl.OpObjectPut) || !basic.AllowedBearerRules(acl.OpObjectGet) {
+		t.Error("only put should have been denied to bearer tokens")
+	}
+	if !basic.Sticky() || !basic.Extendable() {
+		t.Error("expected sticky and extendable")
+	}
+	if _, err := NewBasicACL().Allow(acl.RoleInnerRing).Build(); err == nil {
+		t.Error("expected inner ring rules to be refused")
+	}
+	//replication is fixed for the container's nodes, so this is not an error and changes nothing
+	basic, err = NewBasicACL().Deny(acl.RoleContainer, acl.OpObjectGet).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !basic.IsOpAllowed(acl.OpObjectGet, acl.RoleContainer) {
+		t.Error("container nodes must always be able to replicate")
+	}
+}
+
+func TestExplainBasicACL(t *testing.T) {
+	e := ExplainBasicACL(acl.PublicROExtended.Bits())
+	if e.Preset != acl.NamePublicROExtended || e.Hex != "0x0fbf8cff" || !e.Extendable || e.Final || e.Sticky {
+		t.Errorf("unexpected explanation %+v", e)
+	}
+	if len(e.Operations) != len(BasicACLOps) || len(e.Roles) != len(BasicACLRoles) || len(e.Bearer) != len(BasicACLOps) {
+		t.Fatalf("matrix has the wrong shape %+v", e)
+	}
+	others := e.Roles[len(e.Roles)-1]
+	if others.Role != "others" {
+		t.Fatalf("expected others last, got %s", others.Role)
+	}
+	for i, op := range e.Operations {
+		wantAllowed := op != "put" && op != "delete"
+		if others.Allowed[i] != wantAllowed {
+			t.Errorf("others %s: expected %v", op, wantAllowed)
+		}
+	}
+	if !strings.Contains(e.String(), "public-read") {
+		t.Errorf("expected the preset in the table:\n%s", e)
+	}
+
+	dropbox, err := BasicACLPreset(NameDropbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := ExplainBasicACL(dropbox.Bits()); e.Preset != NameDropbox {
+		t.Errorf("expected the dropbox preset, got %q", e.Preset)
+	}
+	if _, err := BasicACLPreset("0x1fbf8cff"); err != nil {
+		t.Error(err)
+	}
+	if _, err := BasicACLPreset("everyone"); err == nil {
+		t.Error("expected an unknown preset to fail")
+	}
+}
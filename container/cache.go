@@ -0,0 +1,145 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/configwizard/sdk/database"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"sort"
+	"sync"
+)
+
+// ListConcurrency is how many container heads List fetches at once.
+var ListConcurrency = 8
+
+// cachedContainers returns the containers of owner saved by a previous List, marked stale, ordered by id.
+// The cache holds the containers of every owner that has been listed, owner is the encoded user ID.
+func cachedContainers(store database.Store, owner string) []Container {
+	if store == nil {
+		return nil
+	}
+	cached, err := store.SelectAll(database.ContainerBucket)
+	if err != nil {
+		//nothing cached yet
+		return nil
+	}
+	var containers []Container
+	for id, byt := range cached {
+		var c Container
+		if err := json.Unmarshal(byt, &c); err != nil {
+			fmt.Println("could not read cached container ", id, err)
+			continue
+		}
+		if c.Owner != owner {
+			continue
+		}
+		c.Stale = true
+		containers = append(containers, c)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Id < containers[j].Id
+	})
+	return containers
+}
+
+// unlistedContainers returns the cached containers that are not among those listed, they have been deleted elsewhere.
+func unlistedContainers(cached []Container, listed []cid.ID) []Container {
+	ids := make(map[string]struct{}, len(listed))
+	for _, v := range listed {
+		ids[v.String()] = struct{}{}
+	}
+	var removed []Container
+	for _, c := range cached {
+		if _, ok := ids[c.Id]; !ok {
+			removed = append(removed, c)
+		}
+	}
+	return removed
+}
+
+// cachedContainer returns a single cached container.
+func cachedContainer(store database.Store, id string) (Container, bool) {
+	var c Container
+	if store == nil {
+		return c, false
+	}
+	byt, err := store.Select(database.ContainerBucket, id)
+	if err != nil {
+		return c, false
+	}
+	if err := json.Unmarshal(byt, &c); err != nil {
+		fmt.Println("could not read cached container ", id, err)
+		return c, false
+	}
+	return c, true
+}
+
+// unreachableContainer is what to show for a container whose head could not be retrieved: what was cached, marked stale,
+// so the UI keeps the details it already has.
+func unreachableContainer(store database.Store, id string) Container {
+	c, ok := cachedContainer(store, id)
+	if !ok {
+		c = Container{Id: id}
+	}
+	c.Stale = true
+	return c
+}
+
+// cacheContainer saves a freshly retrieved container so it can be shown straight away next time.
+func cacheContainer(store database.Store, c Container) error {
+	if store == nil {
+		return nil
+	}
+	c.Stale = false
+	byt, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return store.Update(database.ContainerBucket, c.Id, byt)
+}
+
+// uncacheContainer forgets a container that no longer exists on the network.
+func uncacheContainer(store database.Store, id string) error {
+	if store == nil {
+		return nil
+	}
+	return store.Delete(database.ContainerBucket, id)
+}
+
+// headContainers retrieves the head of every container with at most concurrency requests at once, calling found as
+// each one arrives. found is never called concurrently.
+func (o *ContainerCaller) headContainers(ctx context.Context, pl *pool.Pool, ids []cid.ID, concurrency int, found func(Container, error)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu      sync.Mutex
+		workers sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			workers.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		workers.Add(1)
+		go func(id cid.ID) {
+			defer func() {
+				<-sem
+				workers.Done()
+			}()
+			localContainer, err := o.SynchronousContainerHead(ctx, id, pl)
+			if err != nil {
+				localContainer = Container{Id: id.String()}
+			}
+			mu.Lock()
+			found(localContainer, err)
+			mu.Unlock()
+		}(id)
+	}
+	workers.Wait()
+}
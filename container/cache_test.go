@@ -0,0 +1,113 @@
+package container
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/configwizard/sdk/database"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+func TestContainerCache(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	if cached := cachedContainers(store, "owner"); len(cached) != 0 {
+		t.Fatalf("expected an empty cache, got %d containers", len(cached))
+	}
+	containers := []Container{
+		{Id: "b", Name: "second", Owner: "owner", BasicACL: 0x1fbfbfff, Attributes: map[string]string{"Name": "second"}},
+		{Id: "a", Name: "first", Owner: "owner", CreatedAt: 1700000000, Stale: true},
+	}
+	for _, c := range containers {
+		if err := cacheContainer(store, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := cachedContainers(store, "owner")
+	if len(cached) != 2 {
+		t.Fatalf("expected 2 cached containers, got %d", len(cached))
+	}
+	if cached[0].Id != "a" || cached[1].Id != "b" {
+		t.Fatalf("cached containers out of order: %s, %s", cached[0].Id, cached[1].Id)
+	}
+	for _, c := range cached {
+		if !c.Stale {
+			t.Errorf("cached container %s should be stale", c.Id)
+		}
+	}
+	if cached[1].BasicACL != 0x1fbfbfff || cached[1].Attributes["Name"] != "second" || cached[0].CreatedAt != 1700000000 {
+		t.Errorf("cached containers lost fields: %+v", cached)
+	}
+
+	if err := uncacheContainer(store, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if cached := cachedContainers(store, "owner"); len(cached) != 1 || cached[0].Id != "b" {
+		t.Fatalf("expected only b to remain, got %+v", cached)
+	}
+}
+
+func TestContainerCacheListsTwoOwners(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	mine := []cid.ID{cid.ID(sha256.Sum256([]byte("mine 1"))), cid.ID(sha256.Sum256([]byte("mine 2")))}
+	theirs := []cid.ID{cid.ID(sha256.Sum256([]byte("theirs 1"))), cid.ID(sha256.Sum256([]byte("theirs 2")))}
+	for _, id := range mine {
+		if err := cacheContainer(store, Container{Id: id.String(), Owner: "me"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range theirs {
+		if err := cacheContainer(store, Container{Id: id.String(), Owner: "them"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//listing someone else's containers must not touch mine
+	cached := cachedContainers(store, "them")
+	if len(cached) != 2 {
+		t.Fatalf("expected their 2 containers, got %+v", cached)
+	}
+	for _, c := range cached {
+		if c.Owner != "them" {
+			t.Errorf("listing them returned %s owned by %s", c.Id, c.Owner)
+		}
+	}
+	removed := unlistedContainers(cached, theirs[:1])
+	if len(removed) != 1 || removed[0].Id != theirs[1].String() {
+		t.Fatalf("expected only their second container to be removed, got %+v", removed)
+	}
+	for _, c := range removed {
+		if err := uncacheContainer(store, c.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cached := cachedContainers(store, "me"); len(cached) != 2 {
+		t.Fatalf("expected my 2 containers to stay cached, got %+v", cached)
+	}
+	if removed := unlistedContainers(cachedContainers(store, "me"), mine); len(removed) != 0 {
+		t.Fatalf("nothing of mine was removed, got %+v", removed)
+	}
+}
+
+func TestContainerCacheWithoutStore(t *testing.T) {
+	if err := cacheContainer(nil, Container{Id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if cached := cachedContainers(nil, "owner"); cached != nil {
+		t.Fatalf("expected nothing without a store, got %+v", cached)
+	}
+}
+
+func TestUnreachableContainer(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	if err := cacheContainer(store, Container{Id: "a", Name: "photos", BasicACL: 0x1fbfbfff}); err != nil {
+		t.Fatal(err)
+	}
+	c := unreachableContainer(store, "a")
+	if c.Name != "photos" || c.BasicACL != 0x1fbfbfff || !c.Stale {
+		t.Errorf("expected the cached container marked stale, got %+v", c)
+	}
+	c = unreachableContainer(store, "b")
+	if c.Id != "b" || !c.Stale {
+		t.Errorf("expected a stale placeholder for an uncached container, got %+v", c)
+	}
+}
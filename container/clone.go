@@ -0,0 +1,166 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/notification"
+	object2 "github.com/configwizard/sdk/object"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/waitgroup"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"strconv"
+)
+
+// CloneTokens are what CloneContainer needs to act for the user. A nil token is fine where the gate account needs none.
+type CloneTokens struct {
+	//Create is a container session token to put the new container
+	Create tokens.Token
+	//SetEACL is a container session token to set the eACL of the new container, only used if the source has one
+	SetEACL tokens.Token
+	//Read is a bearer token to read the source's objects
+	Read tokens.Token
+	//Write returns a bearer token to put objects in the new container, once its ID is known
+	Write func(target cid.ID) (tokens.Token, error)
+}
+
+// CloneReport is emitted as a ContainerCloneUpdate while CloneContainer copies objects, and once more when it is Done.
+type CloneReport struct {
+	SourceID string `json:"sourceID"`
+	TargetID string `json:"targetID"`
+	Total    int    `json:"total"`
+	Copied   int    `json:"copied"`
+	Failed   int    `json:"failed"`
+	Done     bool   `json:"done"`
+	//CopiedIDs (source to copy) and FailedIDs (source to error) are only filled in once Done
+	CopiedIDs map[string]string `json:"copiedIDs,omitempty"`
+	FailedIDs map[string]string `json:"failedIDs,omitempty"`
+}
+
+// cloneAttributes are the source's attributes for the clone, without the name and timestamp Create sets and the NNS
+// name, which is unique, with overrides applied on top. Other system attributes, such as disabled homomorphic hashing,
+// are kept.
+func cloneAttributes(source, overrides map[string]string) map[string]string {
+	attrs := make(map[string]string)
+	for k, v := range source {
+		if k == attributeName || k == attributeTimestamp || k == attributeDomainName || k == attributeDomainZone {
+			continue
+		}
+		attrs[k] = v
+	}
+	for k, v := range overrides {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// CloneContainer creates a new container like the one p.Id names, with the same attributes, basic ACL and eACL, and
+// copies every root object into it. p.Policy is the new container's placement policy. p.Description, p.Attrs,
+// p.Permission and p.Domain change the new container's name, attributes, basic ACL and NNS name. By default the name and
+// basic ACL are the source's, and the NNS name is not copied as names are unique.
+// Encrypted objects are shared again with whoever they were shared with. Objects that fail to copy do not stop the
+// clone, they are listed in the report.
+func (o *ContainerCaller) CloneContainer(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, opts object2.TransferOptions, actionChan chan notification.NewNotification, toks CloneTokens) (CloneReport, error) {
+	var report CloneReport
+	srcId, err := o.containerID(p)
+	if err != nil {
+		actionChan <- o.Notification(
+			"failed to decode container Id",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return report, err
+	}
+	report.SourceID = srcId.String()
+	source, err := o.SynchronousContainerHead(ctx, srcId, p.Pl)
+	if err != nil {
+		actionChan <- o.Notification(
+			"failed to retrieve container",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return report, err
+	}
+
+	target := p
+	target.Id = ""
+	target.Attrs = cloneAttributes(source.Attributes, p.Attrs)
+	if target.Description == "" {
+		target.Description = source.Name
+	}
+	if target.Permission == 0 {
+		target.Permission = acl.Basic(source.BasicACL)
+	}
+	targetId, err := o.create(ctx, target, actionChan, toks.Create)
+	if err != nil {
+		return report, err
+	}
+	report.TargetID = targetId.String()
+
+	if len(source.ExtendedACL.Records) > 0 {
+		restrict := target
+		restrict.Id = targetId.String()
+		restrict.EACLPolicy = ""
+		restrict.EACL = source.ExtendedACL
+		restrict.EACL.ContainerId = targetId.String()
+		if err := o.Restrict(wg, ctx, restrict, actionChan, toks.SetEACL); err != nil {
+			return report, fmt.Errorf("container %s was created but its eACL could not be set: %w", targetId, err)
+		}
+	}
+
+	var writeToken tokens.Token
+	if toks.Write != nil {
+		if writeToken, err = toks.Write(targetId); err != nil {
+			return report, err
+		}
+	}
+	src := object2.ObjectParameter{
+		ContainerId: srcId.String(),
+		PublicKey:   p.PublicKey,
+		GateAccount: p.GateAccount,
+		Pl:          p.Pl,
+	}
+	dst := src
+	dst.ContainerId = targetId.String()
+	copied, err := object2.CopyContainer(wg, ctx, src, dst, opts, toks.Read, writeToken, func(progress object2.CopyReport) {
+		update := report
+		update.Total = progress.Total
+		update.Copied = len(progress.Copied)
+		update.Failed = len(progress.Failed)
+		if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerCloneUpdate, update); err != nil {
+			fmt.Println("could not emit clone progress ", err)
+		}
+	})
+	report.Total = copied.Total
+	report.Copied = len(copied.Copied)
+	report.Failed = len(copied.Failed)
+	report.CopiedIDs = copied.Copied
+	report.FailedIDs = copied.Failed
+	report.Done = true
+	if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerCloneUpdate, report); err != nil {
+		fmt.Println("could not emit clone report ", err)
+	}
+	if err != nil {
+		actionChan <- o.Notification(
+			"failed to clone container",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return report, err
+	}
+	if report.Failed > 0 {
+		actionChan <- o.Notification(
+			"container cloned with failures",
+			strconv.Itoa(report.Failed)+" of "+strconv.Itoa(report.Total)+" objects could not be copied to "+report.TargetID,
+			notification.Error,
+			notification.ActionNotification)
+		return report, nil
+	}
+	actionChan <- o.Notification(
+		"container cloned",
+		strconv.Itoa(report.Copied)+" objects copied to "+report.TargetID,
+		notification.Success,
+		notification.ActionToast)
+	return report, nil
+}
@@ -0,0 +1,26 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneAttributes(t *testing.T) {
+	source := map[string]string{
+		attributeName:                          "photos",
+		attributeTimestamp:                     "1700000000",
+		"__NEOFS__NAME":                        "photos",
+		"__NEOFS__ZONE":                        "container",
+		"__NEOFS__DISABLE_HOMOMORPHIC_HASHING": "true",
+		"Project":                              "holiday",
+		"Region":                               "any",
+	}
+	got := cloneAttributes(source, map[string]string{"Region": "EU"})
+	want := map[string]string{"__NEOFS__DISABLE_HOMOMORPHIC_HASHING": "true", "Project": "holiday", "Region": "EU"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if source["Region"] != "any" {
+		t.Error("the source attributes should not change")
+	}
+}
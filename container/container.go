@@ -13,6 +13,7 @@ import (
 	"github.com/configwizard/sdk/tokens"
 	"github.com/configwizard/sdk/utils"
 	"github.com/configwizard/sdk/waitgroup"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
@@ -21,7 +22,6 @@ import (
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
-	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
@@ -48,6 +48,9 @@ type ContainerAction interface {
 const (
 	attributeName      = "Name"
 	attributeTimestamp = "Timestamp"
+	//the NNS name and zone the container contract registers the container under
+	attributeDomainName = "__NEOFS__NAME"
+	attributeDomainZone = "__NEOFS__ZONE"
 )
 
 type ContainerParameter struct {
@@ -68,6 +71,16 @@ type ContainerParameter struct {
 	ActionOperation  eacl.Operation
 	ExpiryEpoch      uint64
 	EACL             EACLTable
+	//EACLPolicy, if set, is used by Restrict in place of EACL. See ParseEACL for the syntax.
+	EACLPolicy string
+	//Policy is where the container's objects are stored. Empty means DefaultPlacementPolicy.
+	Policy PlacementPolicy
+	//Domain is the name to register the container under in NNS, e.g photos or photos.container
+	Domain string
+	//NamedContainerFee is what the network charges to register Domain (see NetworkFees). Zero asks the network
+	NamedContainerFee uint64
+	//NNS, if set, is used in place of the caller's NNS to resolve Id and check Domain is free
+	NNS NNSResolver
 }
 
 func (c ContainerParameter) Read(p []byte) (n int, err error) {
@@ -130,9 +143,40 @@ type EACLTable struct {
 type Record struct {
 	Action    eacl.Action    `json:"action"`
 	Operation eacl.Operation `json:"operation"`
-	Filters   []eacl.Filter  `json:"filters"`
+	Filters   []Filter       `json:"filters"`
 	Targets   []Target       `json:"targets"`
 }
+
+// Filter is a condition on a header of the request. HeaderType is OBJECT, REQUEST or SERVICE and Match is one of
+// STRING_EQUAL, STRING_NOT_EQUAL, NOT_PRESENT, NUM_GT, NUM_GE, NUM_LT or NUM_LE.
+type Filter struct {
+	HeaderType string `json:"headerType"`
+	Key        string `json:"key"`
+	Match      string `json:"match"`
+	Value      string `json:"value"`
+}
+
+func filterFromNative(f eacl.Filter) Filter {
+	return Filter{
+		HeaderType: f.From().EncodeToString(),
+		Key:        f.Key(),
+		Match:      f.Matcher().EncodeToString(),
+		Value:      f.Value(),
+	}
+}
+
+func (f Filter) native() (eacl.FilterHeaderType, eacl.Match, error) {
+	var from eacl.FilterHeaderType
+	if !from.DecodeString(f.HeaderType) || from == eacl.HeaderTypeUnknown {
+		return from, eacl.MatchUnknown, fmt.Errorf("unknown filter header type %q", f.HeaderType)
+	}
+	var match eacl.Match
+	if !match.DecodeString(f.Match) || match == eacl.MatchUnknown {
+		return from, match, fmt.Errorf("unknown filter match %q", f.Match)
+	}
+	return from, match, nil
+}
+
 type Target struct {
 	Role       eacl.Role `json:"role"`
 	PublicKeys []string  `json:"publicKeys"`
@@ -202,13 +246,23 @@ func ConvertEACLTableToNeoEAcl(eaclTable EACLTable) (*eacl.Table, error) {
 	nativeTable := eacl.CreateTable(cid)
 	for _, rec := range eaclTable.Records {
 		r := eacl.CreateRecord(rec.Action, rec.Operation)
+		for _, f := range rec.Filters {
+			from, match, err := f.native()
+			if err != nil {
+				return nil, err
+			}
+			r.AddFilter(from, match, f.Key, f.Value)
+		}
 		var targets []eacl.Target
 		for _, t := range rec.Targets { //handles the targets on the record automatically
 			newTarget := eacl.NewTarget()
 			newTarget.SetRole(t.Role)
 			var keys []*ecdsa.PublicKey
 			for _, p := range t.PublicKeys {
-				bPubKey, _ := hex.DecodeString(p)
+				bPubKey, err := hex.DecodeString(p)
+				if err != nil {
+					return nil, fmt.Errorf("invalid public key %s: %w", p, err)
+				}
 				var pubKey neofsecdsa.PublicKey
 				if err := pubKey.Decode(bPubKey); err != nil {
 					fmt.Println("error decoding key ", err)
@@ -226,6 +280,29 @@ func ConvertEACLTableToNeoEAcl(eaclTable EACLTable) (*eacl.Table, error) {
 	fmt.Printf("native table is %+v\r\n", nativeTable)
 	return nativeTable, nil
 }
+
+func recordFromNative(r eacl.Record) Record {
+	record := Record{
+		Action:    r.Action(),
+		Operation: r.Operation(),
+	}
+	for _, f := range r.Filters() {
+		record.Filters = append(record.Filters, filterFromNative(f))
+	}
+	for _, t := range r.Targets() {
+		target := Target{
+			Role: t.Role(),
+		}
+		for _, key := range t.BinaryKeys() {
+			target.PublicKeys = append(target.PublicKeys, hex.EncodeToString(key))
+		}
+		record.Targets = append(record.Targets, target)
+	}
+	return record
+}
+
+// ConvertNativeToEACLTable makes the view table from a neoFS native table. Every record is kept, in order, so
+// converting back with ConvertEACLTableToNeoEAcl gives the same table.
 func ConvertNativeToEACLTable(nativeTable eacl.Table) (EACLTable, error) {
 	containerID, isSet := nativeTable.CID()
 	if !isSet {
@@ -234,24 +311,8 @@ func ConvertNativeToEACLTable(nativeTable eacl.Table) (EACLTable, error) {
 	eaclTable := EACLTable{
 		ContainerId: containerID.String(),
 	}
-	for _, r := range nativeTable.Records() { // Assuming Records method that returns []eacl.Record
-		record := Record{
-			Action:    r.Action(),
-			Operation: r.Operation(),
-			Filters:   r.Filters(), // Assuming Filters method
-		}
-		for _, t := range r.Targets() { // Assuming Targets method that returns []eacl.Target
-			target := Target{
-				Role: t.Role(),
-			}
-			for _, key := range t.BinaryKeys() {
-				target.PublicKeys = append(target.PublicKeys, hex.EncodeToString(key))
-			}
-			record.Targets = append(record.Targets, target)
-		}
-		if len(record.Targets) > 0 { //lets get rid of invalid targets
-			eaclTable.Records = append(eaclTable.Records, record)
-		}
+	for _, r := range nativeTable.Records() {
+		eaclTable.Records = append(eaclTable.Records, recordFromNative(r))
 	}
 	return eaclTable, nil
 }
@@ -261,11 +322,16 @@ type Container struct {
 	BasicACL    uint32            `json:"basicACL"`
 	ExtendedACL EACLTable         `json:"extended_acl"`
 	Id          string            `json:"id"`
+	Owner       string            `json:"owner"` //the owner's address
 	Attributes  map[string]string `json:"attributes"`
 	Size        float64           `json:"size"`
 	DomainName  string            `json:"domainName"`
 	DomainZone  string            `json:"domainZone"`
 	CreatedAt   int64             `json:"CreatedAt"`
+	//Stale is set on containers emitted from the cache that have not been refreshed from the network yet
+	Stale bool `json:"stale"`
+	//BasicACLExplanation is BasicACL laid out for display
+	BasicACLExplanation BasicACLExplanation `json:"basicACLExplanation"`
 }
 type ContainerCaller struct {
 	Id        string // Identifier for the object
@@ -277,6 +343,8 @@ type ContainerCaller struct {
 	ContainerEmitter emitter.Emitter //todo - this needs to tell things its complete (async remember)
 	notification.Notifier
 	database.Store
+	//NNS resolves container names given in place of an ID. Without it only IDs are accepted
+	NNS NNSResolver
 }
 
 func (o *ContainerCaller) SetNotifier(notifier notification.Notifier) {
@@ -296,8 +364,8 @@ func (o *ContainerCaller) Delete(wg *waitgroup.WG, ctx context.Context, p Contai
 	} else {
 		sessionToken = tok.SessionToken
 	}
-	var cnrId cid.ID
-	if err := cnrId.DecodeString(p.Id); err != nil {
+	cnrId, err := o.containerID(p)
+	if err != nil {
 		actionChan <- o.Notification(
 			"failed to decode container Id",
 			err.Error(),
@@ -305,6 +373,7 @@ func (o *ContainerCaller) Delete(wg *waitgroup.WG, ctx context.Context, p Contai
 			notification.ActionToast)
 		return err
 	}
+	p.Id = cnrId.String()
 	deleter := client.PrmContainerDelete{}
 	deleter.WithinSession(*sessionToken)
 	sdkCli, err := p.Pl.RawClient()
@@ -348,21 +417,42 @@ func (o *ContainerCaller) Delete(wg *waitgroup.WG, ctx context.Context, p Contai
 }
 
 func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) error {
+	_, err := o.create(ctx, p, actionChan, token)
+	return err
+}
+
+// create is Create, returning the ID of the new container.
+func (o *ContainerCaller) create(ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) (cid.ID, error) {
 	var sessionToken *session.Container
 	if tok, ok := token.(*tokens.ContainerSessionToken); !ok {
 		if tok, ok := token.(*tokens.PrivateContainerSessionToken); !ok {
-			return errors.New(utils.ErrorNoToken)
+			return cid.ID{}, errors.New(utils.ErrorNoToken)
 		} else {
 			sessionToken = tok.SessionToken
 		}
 	} else {
 		sessionToken = tok.SessionToken
 	}
-	const strPolicy = `REP 3`
-	var storagePolicy netmap.PlacementPolicy
-	err := storagePolicy.DecodeString(strPolicy)
+	storagePolicy, err := p.Policy.Build()
 	if err != nil {
-		return err
+		actionChan <- o.Notification(
+			"Could not create container",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return cid.ID{}, err
+	}
+	nm, err := p.Pl.NetMapSnapshot(ctx, client.PrmNetMapSnapshot{})
+	if err != nil {
+		return cid.ID{}, err
+	}
+	if err := ValidatePolicy(storagePolicy, nm); err != nil {
+		actionChan <- o.Notification(
+			"Could not create container",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return cid.ID{}, err
 	}
 	putter := client.PrmContainerPut{}
 	putter.WithinSession(*sessionToken)
@@ -375,12 +465,6 @@ func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p Contai
 	cnr.SetBasicACL(p.Permission) //(p.Permission) //acl.PublicRWExtended)
 	cnr.SetCreationTime(creationTime)
 
-	var rd netmap.ReplicaDescriptor
-	rd.SetNumberOfObjects(1) // placement policy and replicas definition is required
-
-	var pp netmap.PlacementPolicy
-	pp.SetContainerBackupFactor(1)
-	pp.SetReplicas([]netmap.ReplicaDescriptor{rd})
 	cnr.SetPlacementPolicy(storagePolicy)
 	//this should set user specific attributes and not default attributes. I.e block attributes that are 'reserved
 	var domain string
@@ -397,14 +481,37 @@ func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p Contai
 	fmt.Println("time check ", creationTime, fmt.Sprint(creationTime.Unix()), strconv.FormatInt(time.Now().Unix(), 10))
 	createdAt := time.Now().Unix()
 	cnr.SetName(p.Description) //name
+	var cnrDomain container.Domain
+	if p.Domain != "" {
+		var (
+			fee uint64
+			err error
+		)
+		if cnrDomain, fee, err = o.registerDomain(ctx, p); err != nil {
+			actionChan <- o.Notification(
+				"Could not create container",
+				err.Error(),
+				notification.Error,
+				notification.ActionToast)
+			return cid.ID{}, err
+		}
+		//the container contract registers the name in NNS when it sees these attributes
+		cnr.WriteDomain(cnrDomain)
+		domain = cnrDomain.Name()
+		actionChan <- o.Notification(
+			"registering container name",
+			"registering "+cnrDomain.Name()+"."+cnrDomain.Zone()+" costs "+fixedn.Fixed8(fee).String()+" GAS",
+			notification.Info,
+			notification.ActionToast)
+	}
 	if err := client.SyncContainerWithNetwork(p.Ctx, &cnr, p.Pl); err != nil {
-		fmt.Println("sync container with the network state: %s", err)
+		fmt.Println("sync container with the network state: ", err)
 		actionChan <- o.Notification(
 			"Could not create container",
 			"Error syncing with network "+err.Error(),
 			notification.Error,
 			notification.ActionToast)
-		return err
+		return cid.ID{}, err
 	}
 	gateSigner := user.NewAutoIDSignerRFC6979(p.GateAccount.PrivateKey().PrivateKey) //fix me is this correct signer?
 	sdkCli, err := p.Pl.RawClient()
@@ -414,7 +521,7 @@ func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p Contai
 			"Error connecting to network "+err.Error(),
 			notification.Error,
 			notification.ActionToast)
-		return err
+		return cid.ID{}, err
 	}
 	wait := waiter.NewContainerPutWaiter(sdkCli, waiter.DefaultPollInterval)
 	ctx, cancel := context.WithTimeout(p.Ctx, 120*time.Second)
@@ -433,17 +540,18 @@ func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p Contai
 			err.Error(),
 			notification.Error,
 			notification.ActionToast)
-		return err
+		return cid.ID{}, err
 	}
 
 	localContainer := Container{
-		Name:       p.Name(),
-		Id:         idCnr.String(),
-		Attributes: p.Attrs,
-		BasicACL:   uint32(p.Permission),
-		DomainName: domain, //fixme = domains
-		//DomainZone: remoteContainer.ReadDomain().Zone(),
-		CreatedAt: createdAt,
+		Name:                p.Name(),
+		Id:                  idCnr.String(),
+		Attributes:          p.Attrs,
+		BasicACL:            uint32(p.Permission),
+		BasicACLExplanation: ExplainBasicACL(uint32(p.Permission)),
+		DomainName:          domain,
+		DomainZone:          cnrDomain.Zone(),
+		CreatedAt:           createdAt,
 	}
 	if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerAddUpdate, localContainer); err != nil {
 		actionChan <- o.Notification(
@@ -451,19 +559,19 @@ func (o *ContainerCaller) Create(wg *waitgroup.WG, ctx context.Context, p Contai
 			err.Error(),
 			notification.Error,
 			notification.ActionNotification)
-		return err
+		return cid.ID{}, err
 	}
 	actionChan <- o.Notification(
 		"container "+p.Name()+" created",
 		idCnr.String(),
 		notification.Success,
 		notification.ActionToast)
-	return nil
+	return idCnr, nil
 }
 
 func (o *ContainerCaller) Restrict(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) error {
-	var cnrId cid.ID
-	if err := cnrId.DecodeString(p.Id); err != nil {
+	cnrId, err := o.containerID(p)
+	if err != nil {
 		actionChan <- o.Notification(
 			"failed to decode container Id",
 			err.Error(),
@@ -471,6 +579,7 @@ func (o *ContainerCaller) Restrict(wg *waitgroup.WG, ctx context.Context, p Cont
 			notification.ActionToast)
 		return err
 	}
+	p.Id = cnrId.String()
 	var sessionToken *session.Container
 	if tok, ok := token.(*tokens.ContainerSessionToken); !ok {
 		if tok, ok := token.(*tokens.PrivateContainerSessionToken); !ok {
@@ -482,8 +591,18 @@ func (o *ContainerCaller) Restrict(wg *waitgroup.WG, ctx context.Context, p Cont
 		sessionToken = tok.SessionToken
 	}
 
-	eaclTable, err := ConvertEACLTableToNeoEAcl(p.EACL)
-	if err != nil {
+	var eaclTable *eacl.Table
+	if p.EACLPolicy != "" {
+		eaclTable, err = ParseEACL(p.Id, p.EACLPolicy)
+		if err != nil {
+			actionChan <- o.Notification(
+				"invalid eACL policy",
+				err.Error(),
+				notification.Error,
+				notification.ActionToast)
+			return err
+		}
+	} else if eaclTable, err = ConvertEACLTableToNeoEAcl(p.EACL); err != nil {
 		return err
 	}
 	var setEACLOpts client.PrmContainerSetEACL
@@ -529,16 +648,21 @@ func (o *ContainerCaller) SynchronousContainerHead(ctx context.Context, cnrId ci
 	if strings.HasSuffix(remoteContainer.Attribute("DOMAIN"), ".neo") {
 		domain = remoteContainer.Attribute("DOMAIN")
 	}
+	if d := remoteContainer.ReadDomain(); d.Name() != "" {
+		domain = d.Name()
+	}
 	//t, err := time.Parse(time.RFC3339, remoteContainer.CreatedAt().Unix())
 	//head is going to send a container object, just this time with the content populated
 	localContainer = Container{
-		BasicACL:   remoteContainer.BasicACL().Bits(),
-		Name:       remoteContainer.Name(),
-		Id:         cnrId.String(),
-		Attributes: make(map[string]string),
-		DomainName: domain,
-		DomainZone: remoteContainer.ReadDomain().Zone(),
-		CreatedAt:  remoteContainer.CreatedAt().Unix(),
+		BasicACL:            remoteContainer.BasicACL().Bits(),
+		BasicACLExplanation: ExplainBasicACL(remoteContainer.BasicACL().Bits()),
+		Name:                remoteContainer.Name(),
+		Id:                  cnrId.String(),
+		Owner:               remoteContainer.Owner().EncodeToString(),
+		Attributes:          make(map[string]string),
+		DomainName:          domain,
+		DomainZone:          remoteContainer.ReadDomain().Zone(),
+		CreatedAt:           remoteContainer.CreatedAt().Unix(),
 	}
 	remoteContainer.IterateAttributes(func(k string, v string) {
 		localContainer.Attributes[k] = v
@@ -557,8 +681,8 @@ func (o *ContainerCaller) SynchronousContainerHead(ctx context.Context, cnrId ci
 	return localContainer, nil
 }
 func (o *ContainerCaller) Head(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, _ tokens.Token) error {
-	var cnrId cid.ID
-	if err := cnrId.DecodeString(p.Id); err != nil {
+	cnrId, err := o.containerID(p)
+	if err != nil {
 		actionChan <- o.Notification(
 			"failed to decode container Id",
 			err.Error(),
@@ -566,6 +690,7 @@ func (o *ContainerCaller) Head(wg *waitgroup.WG, ctx context.Context, p Containe
 			notification.ActionToast)
 		return err
 	}
+	p.Id = cnrId.String()
 	localContainer, err := o.SynchronousContainerHead(p.Ctx, cnrId, p.Pl)
 	if err != nil {
 		actionChan <- o.Notification(
@@ -592,13 +717,20 @@ func (o *ContainerCaller) Head(wg *waitgroup.WG, ctx context.Context, p Containe
 	return nil
 }
 
-// List responds with all the IDs of containers owned by the public key.
+// List emits every container owned by the public key. Containers cached by a previous List are emitted first,
+// marked Stale, then each is emitted again in full as its head is retrieved and the cache is refreshed.
 func (o *ContainerCaller) List(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) error {
 	var issuer user.ID
 	err := issuer.DecodeString(p.ContainerSubject)
 	if err != nil {
 		issuer = user.ResolveFromECDSAPublicKey(p.PublicKey)
 	}
+	cached := cachedContainers(o.Store, issuer.EncodeToString())
+	for _, c := range cached {
+		if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerAddUpdate, c); err != nil {
+			fmt.Println("error emitting cached container ", err)
+		}
+	}
 	fmt.Println("user listing containers", issuer)
 	lst := client.PrmContainerList{}
 	r, err := p.Pl.ContainerList(ctx, issuer, lst)
@@ -610,22 +742,37 @@ func (o *ContainerCaller) List(wg *waitgroup.WG, ctx context.Context, p Containe
 			notification.ActionToast)
 		return err
 	}
-	//we need to now emit this list one at a time as we receive them (or as one array?)
-	for _, v := range r { //we can manage this synchronously i believe.
-		fmt.Printf("emitting here %+v\r\n", v.String())
-		err := p.ContainerEmitter.Emit(ctx, emitter.ContainerAddUpdate, Container{Id: v.String()})
+	//anything of this owner's cached that the network no longer lists has been deleted elsewhere
+	for _, c := range unlistedContainers(cached, r) {
+		if err := uncacheContainer(o.Store, c.Id); err != nil {
+			fmt.Println("could not remove cached container ", c.Id, err)
+		}
+		if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerRemoveUpdate, Container{Id: c.Id}); err != nil {
+			fmt.Println("error emitting removed container ", err)
+		}
+	}
+	o.headContainers(ctx, p.Pl, r, ListConcurrency, func(localContainer Container, err error) {
 		if err != nil {
-			fmt.Println("error emitting new object ", p)
+			//still show the container, the UI can ask for its head again
+			fmt.Println("could not retrieve container head ", localContainer.Id, err)
+			localContainer = unreachableContainer(o.Store, localContainer.Id)
+		} else {
+			//the size is not part of the head, keep whatever Usage last counted
+			localContainer.Size = float64(cachedUsage(o.Store, localContainer.Id).Bytes)
+			if err := cacheContainer(o.Store, localContainer); err != nil {
+				fmt.Println("could not cache container ", localContainer.Id, err)
+			}
+		}
+		if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerAddUpdate, localContainer); err != nil {
+			fmt.Println("error emitting new container ", localContainer.Id)
 			actionChan <- o.Notification(
 				"Could not list containers",
 				"could not list containers "+err.Error(),
 				notification.Error,
 				notification.ActionToast)
 		}
-		time.Sleep(100 * time.Millisecond)
-	}
-
-	return nil
+	})
+	return ctx.Err()
 }
 
 func (c *ContainerCaller) Read(wg *waitgroup.WG, ctx context.Context, p ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) error {
@@ -647,10 +794,11 @@ func (c *ContainerCaller) Read(wg *waitgroup.WG, ctx context.Context, p Containe
 		prms.WithBearerToken(*bToken)
 	}
 
-	var cnrId cid.ID
-	if err := cnrId.DecodeString(p.Id); err != nil {
+	cnrId, err := c.containerID(p)
+	if err != nil {
 		return errors.New(utils.ErrorNotFound) //todo - more specific?
 	}
+	p.Id = cnrId.String()
 
 	// todo: list all containers
 	//wgMessage := "containerRead"
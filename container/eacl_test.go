@@ -0,0 +1,150 @@
+package container
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+)
+
+// randomTable is an EACLTable with random records that quick can generate.
+type randomTable EACLTable
+
+var (
+	testHeaderTypes = []string{"OBJECT", "REQUEST", "SERVICE"}
+	testMatches     = []string{"STRING_EQUAL", "STRING_NOT_EQUAL", "NOT_PRESENT", "NUM_GT", "NUM_GE", "NUM_LT", "NUM_LE"}
+	testRoles       = []eacl.Role{eacl.RoleUnknown, eacl.RoleUser, eacl.RoleSystem, eacl.RoleOthers}
+)
+
+func randomString(r *rand.Rand) string {
+	runes := []rune("abcXYZ019 _-:/$.\"\\é世")
+	s := make([]rune, r.Intn(12))
+	for i := range s {
+		s[i] = runes[r.Intn(len(runes))]
+	}
+	return string(s)
+}
+
+func randomKey(r *rand.Rand) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), r)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y))
+}
+
+func (randomTable) Generate(r *rand.Rand, _ int) reflect.Value {
+	table := randomTable{ContainerId: testContainerID()}
+	for i := r.Intn(6); i > 0; i-- {
+		record := Record{
+			Action:    eacl.Action(1 + r.Intn(2)),
+			Operation: eacl.Operation(1 + r.Intn(7)),
+		}
+		for j := r.Intn(4); j > 0; j-- {
+			record.Filters = append(record.Filters, Filter{
+				HeaderType: testHeaderTypes[r.Intn(len(testHeaderTypes))],
+				Key:        randomString(r),
+				Match:      testMatches[r.Intn(len(testMatches))],
+				Value:      randomString(r),
+			})
+		}
+		//records without targets are kept too
+		for j := r.Intn(3); j > 0; j-- {
+			target := Target{Role: testRoles[r.Intn(len(testRoles))]}
+			for k := r.Intn(3); k > 0; k-- {
+				target.PublicKeys = append(target.PublicKeys, randomKey(r))
+			}
+			record.Targets = append(record.Targets, target)
+		}
+		table.Records = append(table.Records, record)
+	}
+	return reflect.ValueOf(table)
+}
+
+func TestEACLTableRoundTrip(t *testing.T) {
+	roundTrip := func(generated randomTable) bool {
+		table := EACLTable(generated)
+		native, err := ConvertEACLTableToNeoEAcl(table)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		back, err := ConvertNativeToEACLTable(*native)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		if !reflect.DeepEqual(table, back) {
+			t.Logf("want %+v\ngot  %+v", table, back)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 200}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEACLTableSurvivesJSONAndTheWire(t *testing.T) {
+	roundTrip := func(generated randomTable) bool {
+		table := EACLTable(generated)
+		byt, err := json.Marshal(table)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		var decoded EACLTable
+		if err := json.Unmarshal(byt, &decoded); err != nil {
+			t.Log(err)
+			return false
+		}
+		native, err := ConvertEACLTableToNeoEAcl(decoded)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		//what the network stores
+		wire, err := native.Marshal()
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		var received eacl.Table
+		if err := received.Unmarshal(wire); err != nil {
+			t.Log(err)
+			return false
+		}
+		back, err := ConvertNativeToEACLTable(received)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		if !reflect.DeepEqual(table, back) {
+			t.Logf("want %+v\ngot  %+v", table, back)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 200}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConvertEACLTableRejectsUnknownFilters(t *testing.T) {
+	for _, f := range []Filter{
+		{HeaderType: "BODY", Key: "k", Match: "STRING_EQUAL"},
+		{HeaderType: "OBJECT", Key: "k", Match: "LIKE"},
+		{HeaderType: "HEADER_UNSPECIFIED", Key: "k", Match: "STRING_EQUAL"},
+	} {
+		table := EACLTable{ContainerId: testContainerID(), Records: []Record{{Action: eacl.ActionDeny, Operation: eacl.OperationGet, Filters: []Filter{f}}}}
+		if _, err := ConvertEACLTableToNeoEAcl(table); err == nil {
+			t.Errorf("expected %+v to be rejected", f)
+		}
+	}
+}
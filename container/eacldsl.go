@@ -0,0 +1,548 @@
+package container
+
+import (
+	"encoding/hex"
+	"fmt"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"strconv"
+	"strings"
+)
+
+/*
+An eACL policy is one rule per line, checked in order. Blank lines and anything after # are ignored.
+
+	allow get,head to others where $Object:FileName == "public/index.html"
+	deny put,delete to others
+	allow * to key:031a6c6fbbdf02ca351745fa86b9ba5a9452d785ac4f7fc2b7548ca2a46c4fcf4a
+	deny get to others where $Object:Confidential absent and $Object:payloadLength > 1048576
+
+Operations are get, head, put, delete, search, range and rangehash, or * for all of them.
+Targets are user, system, others or key:<hex public key>, or nobody for a rule that applies to no one.
+Conditions compare a header with ==, !=, >, >=, < or <=, or test that it is absent. $Object:<name> is an object
+attribute, or one of the object's own fields such as $Object:ownerID. $Request:<name> is a request X-Header.
+*/
+
+// EACLSyntaxError is a mistake in an eACL policy, with the line it is on.
+type EACLSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e EACLSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var eaclOperationNames = []struct {
+	name string
+	op   eacl.Operation
+}{
+	{"get", eacl.OperationGet},
+	{"head", eacl.OperationHead},
+	{"put", eacl.OperationPut},
+	{"delete", eacl.OperationDelete},
+	{"search", eacl.OperationSearch},
+	{"range", eacl.OperationRange},
+	{"rangehash", eacl.OperationRangeHash},
+}
+
+func parseEACLOperation(s string) (eacl.Operation, bool) {
+	s = strings.ReplaceAll(strings.ToLower(s), "_", "")
+	for _, o := range eaclOperationNames {
+		if o.name == s {
+			return o.op, true
+		}
+	}
+	return eacl.OperationUnknown, false
+}
+
+func eaclOperationName(op eacl.Operation) string {
+	for _, o := range eaclOperationNames {
+		if o.op == op {
+			return o.name
+		}
+	}
+	return op.String()
+}
+
+var eaclRoleNames = map[string]eacl.Role{
+	"user":   eacl.RoleUser,
+	"system": eacl.RoleSystem,
+	"others": eacl.RoleOthers,
+}
+
+func eaclRoleName(role eacl.Role) string {
+	for name, r := range eaclRoleNames {
+		if r == role {
+			return name
+		}
+	}
+	return ""
+}
+
+var eaclMatchSymbols = []struct {
+	symbol string
+	match  eacl.Match
+}{
+	{"==", eacl.MatchStringEqual},
+	{"!=", eacl.MatchStringNotEqual},
+	{">=", eacl.MatchNumGE},
+	{"<=", eacl.MatchNumLE},
+	{">", eacl.MatchNumGT},
+	{"<", eacl.MatchNumLT},
+}
+
+func eaclMatchIsNumeric(m eacl.Match) bool {
+	switch m {
+	case eacl.MatchNumGT, eacl.MatchNumGE, eacl.MatchNumLT, eacl.MatchNumLE:
+		return true
+	}
+	return false
+}
+
+// the object's own fields, which eACL filters refer to by their full $Object: key rather than as attributes
+var eaclObjectFields = []string{
+	eacl.FilterObjectVersion,
+	eacl.FilterObjectID,
+	eacl.FilterObjectContainerID,
+	eacl.FilterObjectOwnerID,
+	eacl.FilterObjectCreationEpoch,
+	eacl.FilterObjectPayloadSize,
+	eacl.FilterObjectPayloadChecksum,
+	eacl.FilterObjectType,
+	eacl.FilterObjectPayloadHomomorphicChecksum,
+}
+
+const (
+	eaclObjectPrefix  = "$Object:"
+	eaclRequestPrefix = "$Request:"
+	eaclServicePrefix = "$Service:"
+)
+
+// parseEACLHeader turns a header reference into the header type and filter key.
+func parseEACLHeader(ref string) (eacl.FilterHeaderType, string, error) {
+	switch {
+	case strings.HasPrefix(ref, eaclObjectPrefix):
+		for _, field := range eaclObjectFields {
+			if strings.EqualFold(ref, field) {
+				return eacl.HeaderFromObject, field, nil
+			}
+		}
+		key := strings.TrimPrefix(ref, eaclObjectPrefix)
+		if key == "" {
+			return 0, "", fmt.Errorf("%s needs an attribute name", eaclObjectPrefix)
+		}
+		return eacl.HeaderFromObject, key, nil
+	case strings.HasPrefix(ref, eaclRequestPrefix):
+		key := strings.TrimPrefix(ref, eaclRequestPrefix)
+		if key == "" {
+			return 0, "", fmt.Errorf("%s needs a header name", eaclRequestPrefix)
+		}
+		return eacl.HeaderFromRequest, key, nil
+	case strings.HasPrefix(ref, eaclServicePrefix):
+		key := strings.TrimPrefix(ref, eaclServicePrefix)
+		if key == "" {
+			return 0, "", fmt.Errorf("%s needs a header name", eaclServicePrefix)
+		}
+		return eacl.HeaderFromService, key, nil
+	}
+	return 0, "", fmt.Errorf("unknown header %q, expected %s, %s or %s", ref, eaclObjectPrefix+"<name>", eaclRequestPrefix+"<name>", eaclServicePrefix+"<name>")
+}
+
+func formatEACLHeader(from eacl.FilterHeaderType, key string) string {
+	switch from {
+	case eacl.HeaderFromRequest:
+		return eaclRequestPrefix + key
+	case eacl.HeaderFromService:
+		return eaclServicePrefix + key
+	}
+	if strings.HasPrefix(key, eaclObjectPrefix) {
+		return key
+	}
+	return eaclObjectPrefix + key
+}
+
+type eaclTokenKind int
+
+const (
+	eaclWord eaclTokenKind = iota
+	eaclString
+	eaclSymbol
+	eaclComma
+)
+
+type eaclToken struct {
+	kind eaclTokenKind
+	text string
+}
+
+func isEACLSymbol(c byte) bool {
+	return strings.IndexByte("=!<>^", c) >= 0
+}
+
+// tokeniseEACLLine splits a line into words, quoted strings, comparison symbols and commas, stopping at a comment.
+func tokeniseEACLLine(line string) ([]eaclToken, error) {
+	var toks []eaclToken
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			return toks, nil
+		case c == ',':
+			toks = append(toks, eaclToken{eaclComma, ","})
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(line); j++ {
+				if line[j] == '\\' {
+					j++
+					continue
+				}
+				if line[j] == '"' {
+					break
+				}
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated string %s", line[i:])
+			}
+			s, err := strconv.Unquote(line[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", line[i:j+1])
+			}
+			toks = append(toks, eaclToken{eaclString, s})
+			i = j + 1
+		case isEACLSymbol(c):
+			j := i
+			for j < len(line) && isEACLSymbol(line[j]) {
+				j++
+			}
+			toks = append(toks, eaclToken{eaclSymbol, line[i:j]})
+			i = j
+		default:
+			j := i
+			for j < len(line) && !strings.ContainsRune(" \t\r#,\"", rune(line[j])) && !isEACLSymbol(line[j]) {
+				j++
+			}
+			toks = append(toks, eaclToken{eaclWord, line[i:j]})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+type eaclLineParser struct {
+	toks []eaclToken
+	pos  int
+}
+
+func (p *eaclLineParser) next() (eaclToken, bool) {
+	if p.pos >= len(p.toks) {
+		return eaclToken{}, false
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, true
+}
+
+func (p *eaclLineParser) peek() (eaclToken, bool) {
+	if p.pos >= len(p.toks) {
+		return eaclToken{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+// list reads words separated by commas.
+func (p *eaclLineParser) list(what string) ([]string, error) {
+	var words []string
+	for {
+		t, ok := p.next()
+		if !ok || t.kind != eaclWord {
+			return nil, fmt.Errorf("expected %s", what)
+		}
+		words = append(words, t.text)
+		if t, ok := p.peek(); !ok || t.kind != eaclComma {
+			return words, nil
+		}
+		p.pos++
+	}
+}
+
+// parseEACLLine parses one rule into a record per operation.
+func parseEACLLine(line string) ([]*eacl.Record, error) {
+	toks, err := tokeniseEACLLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, nil
+	}
+	p := &eaclLineParser{toks: toks}
+
+	t, _ := p.next()
+	var action eacl.Action
+	switch strings.ToLower(t.text) {
+	case "allow":
+		action = eacl.ActionAllow
+	case "deny":
+		action = eacl.ActionDeny
+	default:
+		return nil, fmt.Errorf("expected allow or deny, found %q", t.text)
+	}
+
+	opNames, err := p.list("an operation")
+	if err != nil {
+		return nil, err
+	}
+	var ops []eacl.Operation
+	for _, name := range opNames {
+		if name == "*" {
+			for _, o := range eaclOperationNames {
+				ops = append(ops, o.op)
+			}
+			continue
+		}
+		op, ok := parseEACLOperation(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		ops = append(ops, op)
+	}
+
+	if t, ok := p.next(); !ok || strings.ToLower(t.text) != "to" {
+		return nil, fmt.Errorf("expected to after the operations")
+	}
+	targetNames, err := p.list("a target")
+	if err != nil {
+		return nil, err
+	}
+	var targets []eacl.Target
+	var keys [][]byte
+	for _, name := range targetNames {
+		if strings.ToLower(name) == "nobody" {
+			if len(targetNames) > 1 {
+				return nil, fmt.Errorf("nobody cannot be combined with other targets")
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(name), "key:") {
+			key, err := hex.DecodeString(name[len("key:"):])
+			if err != nil {
+				return nil, fmt.Errorf("public key %q is not hex", name[len("key:"):])
+			}
+			var pubKey neofsecdsa.PublicKey
+			if err := pubKey.Decode(key); err != nil {
+				return nil, fmt.Errorf("invalid public key %s: %w", name[len("key:"):], err)
+			}
+			keys = append(keys, key)
+			continue
+		}
+		role, ok := eaclRoleNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown target %q, expected user, system, others or key:<hex>", name)
+		}
+		target := eacl.NewTarget()
+		target.SetRole(role)
+		targets = append(targets, *target)
+	}
+	if len(keys) > 0 {
+		target := eacl.NewTarget()
+		target.SetBinaryKeys(keys)
+		targets = append(targets, *target)
+	}
+
+	type condition struct {
+		from  eacl.FilterHeaderType
+		match eacl.Match
+		key   string
+		value string
+	}
+	var conditions []condition
+	if t, ok := p.next(); ok {
+		if strings.ToLower(t.text) != "where" {
+			return nil, fmt.Errorf("expected where or the end of the line, found %q", t.text)
+		}
+		for {
+			ref, ok := p.next()
+			if !ok || ref.kind != eaclWord {
+				return nil, fmt.Errorf("expected a header such as $Object:FileName")
+			}
+			from, key, err := parseEACLHeader(ref.text)
+			if err != nil {
+				return nil, err
+			}
+			c := condition{from: from, key: key}
+			op, ok := p.next()
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("expected a comparison after %s", ref.text)
+			case op.kind == eaclWord && strings.ToLower(op.text) == "absent":
+				c.match = eacl.MatchNotPresent
+			case op.kind == eaclSymbol && op.text == "^=":
+				return nil, fmt.Errorf("eACL cannot match a prefix, use == with the full value")
+			case op.kind == eaclSymbol:
+				for _, m := range eaclMatchSymbols {
+					if m.symbol == op.text {
+						c.match = m.match
+					}
+				}
+				if c.match == eacl.MatchUnknown {
+					return nil, fmt.Errorf("unknown comparison %q", op.text)
+				}
+				value, ok := p.next()
+				if !ok || (value.kind != eaclString && value.kind != eaclWord) {
+					return nil, fmt.Errorf("expected a value after %s", op.text)
+				}
+				if eaclMatchIsNumeric(c.match) {
+					if _, err := strconv.ParseUint(value.text, 10, 64); err != nil {
+						return nil, fmt.Errorf("%s compares numbers, %q is not one", op.text, value.text)
+					}
+				}
+				c.value = value.text
+			default:
+				return nil, fmt.Errorf("expected a comparison after %s, found %q", ref.text, op.text)
+			}
+			conditions = append(conditions, c)
+			and, ok := p.next()
+			if !ok {
+				break
+			}
+			if strings.ToLower(and.text) != "and" {
+				return nil, fmt.Errorf("expected and or the end of the line, found %q", and.text)
+			}
+		}
+	}
+
+	var records []*eacl.Record
+	for _, op := range ops {
+		r := eacl.CreateRecord(action, op)
+		for _, c := range conditions {
+			r.AddFilter(c.from, c.match, c.key, c.value)
+		}
+		r.SetTargets(targets...)
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// ParseEACL parses an eACL policy into a table for the container cnrID. An empty cnrID leaves the table unbound.
+// Errors are EACLSyntaxError, giving the line of the mistake.
+func ParseEACL(cnrID, policy string) (*eacl.Table, error) {
+	table := eacl.NewTable()
+	if cnrID != "" {
+		var id cid.ID
+		if err := id.DecodeString(cnrID); err != nil {
+			return nil, fmt.Errorf("invalid container ID: %w", err)
+		}
+		table = eacl.CreateTable(id)
+	}
+	for i, line := range strings.Split(policy, "\n") {
+		records, err := parseEACLLine(line)
+		if err != nil {
+			return nil, EACLSyntaxError{Line: i + 1, Msg: err.Error()}
+		}
+		for _, r := range records {
+			table.AddRecord(r)
+		}
+	}
+	return table, nil
+}
+
+// ParseEACLTable parses an eACL policy into the view table for the container cnrID.
+func ParseEACLTable(cnrID, policy string) (EACLTable, error) {
+	table, err := ParseEACL(cnrID, policy)
+	if err != nil {
+		return EACLTable{}, err
+	}
+	return ConvertNativeToEACLTable(*table)
+}
+
+func formatEACLConditions(filters []Filter) string {
+	var conditions []string
+	for _, f := range filters {
+		from, match, err := f.native()
+		if err != nil {
+			//not something the policy language can say, show it as it is
+			conditions = append(conditions, fmt.Sprintf("%s:%s %s %q", f.HeaderType, f.Key, f.Match, f.Value))
+			continue
+		}
+		header := formatEACLHeader(from, f.Key)
+		if match == eacl.MatchNotPresent {
+			conditions = append(conditions, header+" absent")
+			continue
+		}
+		symbol := match.String()
+		for _, m := range eaclMatchSymbols {
+			if m.match == match {
+				symbol = m.symbol
+			}
+		}
+		value := strconv.Quote(f.Value)
+		if eaclMatchIsNumeric(match) {
+			value = f.Value
+		}
+		conditions = append(conditions, header+" "+symbol+" "+value)
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(conditions, " and ")
+}
+
+func formatEACLTargets(targets []Target) string {
+	var names []string
+	for _, t := range targets {
+		if name := eaclRoleName(t.Role); name != "" {
+			names = append(names, name)
+		}
+		for _, key := range t.PublicKeys {
+			names = append(names, "key:"+key)
+		}
+	}
+	//a record without targets applies to no one
+	if len(names) == 0 {
+		return "nobody"
+	}
+	return strings.Join(names, ",")
+}
+
+func formatEACLRecords(records []Record) string {
+	var b strings.Builder
+	//consecutive records that differ only by operation are written as one rule
+	for i := 0; i < len(records); {
+		r := records[i]
+		rest := " to " + formatEACLTargets(r.Targets) + formatEACLConditions(r.Filters)
+		ops := []string{eaclOperationName(r.Operation)}
+		j := i + 1
+		for ; j < len(records); j++ {
+			next := records[j]
+			if next.Action != r.Action || " to "+formatEACLTargets(next.Targets)+formatEACLConditions(next.Filters) != rest {
+				break
+			}
+			ops = append(ops, eaclOperationName(next.Operation))
+		}
+		action := "allow"
+		if r.Action == eacl.ActionDeny {
+			action = "deny"
+		}
+		b.WriteString(action + " " + strings.Join(ops, ",") + rest + "\n")
+		i = j
+	}
+	return b.String()
+}
+
+// FormatEACLTable writes a view table as an eACL policy that ParseEACLTable reads back into the same table.
+func FormatEACLTable(table EACLTable) string {
+	return formatEACLRecords(table.Records)
+}
+
+// FormatEACL writes a table as an eACL policy.
+func FormatEACL(table eacl.Table) string {
+	var records []Record
+	for _, r := range table.Records() {
+		records = append(records, recordFromNative(r))
+	}
+	return formatEACLRecords(records)
+}
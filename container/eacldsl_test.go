@@ -0,0 +1,132 @@
+package container
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+)
+
+func testContainerID() string {
+	return cid.ID(sha256.Sum256([]byte("eacl dsl"))).String()
+}
+
+func testPublicKeyHex(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y))
+}
+
+func TestParseEACL(t *testing.T) {
+	key := testPublicKeyHex(t)
+	policy := `
+# public files can be read by anyone
+allow get,head to others where $Object:FileName == "public/index.html"
+deny put to others
+
+allow * to key:` + key + `
+deny get to user,system where $Object:payloadLength > 1048576 and $Request:X-Secret absent
+`
+	table, err := ParseEACLTable(testContainerID(), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Records) != 2+1+7+1 {
+		t.Fatalf("expected 11 records, got %d", len(table.Records))
+	}
+	first := table.Records[0]
+	if first.Action != eacl.ActionAllow || first.Operation != eacl.OperationGet {
+		t.Errorf("first record is %s %s", first.Action, first.Operation)
+	}
+	if len(first.Targets) != 1 || first.Targets[0].Role != eacl.RoleOthers {
+		t.Errorf("first record targets %+v", first.Targets)
+	}
+	if len(first.Filters) != 1 || first.Filters[0] != (Filter{HeaderType: "OBJECT", Key: "FileName", Match: "STRING_EQUAL", Value: "public/index.html"}) {
+		t.Errorf("first record filters %+v", first.Filters)
+	}
+	keyRecord := table.Records[3]
+	if len(keyRecord.Targets) != 1 || len(keyRecord.Targets[0].PublicKeys) != 1 || keyRecord.Targets[0].PublicKeys[0] != key {
+		t.Errorf("key record targets %+v", keyRecord.Targets)
+	}
+	last := table.Records[10]
+	if len(last.Targets) != 2 || len(last.Filters) != 2 {
+		t.Fatalf("last record %+v", last)
+	}
+	if last.Filters[0].Key != eacl.FilterObjectPayloadSize || last.Filters[0].Match != "NUM_GT" {
+		t.Errorf("payload length filter %+v", last.Filters[0])
+	}
+	if last.Filters[1].HeaderType != "REQUEST" || last.Filters[1].Match != "NOT_PRESENT" {
+		t.Errorf("request filter %+v", last.Filters[1])
+	}
+}
+
+func TestParseEACLErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy string
+		line   int
+		msg    string
+	}{
+		{"bad action", "permit get to others", 1, "allow or deny"},
+		{"bad operation", "\nallow fetch to others", 2, "unknown operation"},
+		{"missing to", "allow get others", 1, "expected to"},
+		{"bad target", "allow get to everyone", 1, "unknown target"},
+		{"bad key", "allow get to key:zz", 1, "not hex"},
+		{"nobody and others", "allow get to nobody,others", 1, "cannot be combined"},
+		{"prefix", "# ok\n\nallow get to others where $Object:FileName ^= \"public/\"", 3, "prefix"},
+		{"numeric", "deny get to others where $Object:payloadLength > big", 1, "numbers"},
+		{"missing value", "deny get to others where $Object:FileName ==", 1, "value"},
+		{"bad header", "deny get to others where FileName == \"a\"", 1, "unknown header"},
+		{"unterminated", "deny get to others where $Object:FileName == \"a", 1, "unterminated"},
+		{"trailing", "deny get to others where $Object:FileName == \"a\" or", 1, "expected and"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseEACL("", c.policy)
+			var syntaxErr EACLSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a syntax error, got %v", err)
+			}
+			if syntaxErr.Line != c.line {
+				t.Errorf("expected line %d, got %d (%s)", c.line, syntaxErr.Line, err)
+			}
+			if !strings.Contains(err.Error(), c.msg) {
+				t.Errorf("expected %q in %q", c.msg, err)
+			}
+		})
+	}
+}
+
+func TestFormatEACLRoundTrip(t *testing.T) {
+	key := testPublicKeyHex(t)
+	policy := `allow get,head to others where $Object:FileName == "say \"hi\".txt"
+deny put,delete to others
+allow get,head,put,delete,search,range,rangehash to key:` + key + `
+deny get to user,system where $Object:payloadLength >= 10 and $Request:X-Secret absent
+deny search to nobody
+`
+	table, err := ParseEACLTable(testContainerID(), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted := FormatEACLTable(table)
+	if formatted != policy {
+		t.Fatalf("formatted policy differs\nwant:\n%s\ngot:\n%s", policy, formatted)
+	}
+	native, err := ParseEACL(testContainerID(), formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FormatEACL(*native) != policy {
+		t.Errorf("native table formats differently:\n%s", FormatEACL(*native))
+	}
+}
@@ -0,0 +1,227 @@
+package container
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"math/big"
+	"strconv"
+)
+
+// Requester is who a request comes from. PublicKey is the compressed key the request is signed with, and is only
+// needed to match records that target keys.
+type Requester struct {
+	Role      eacl.Role
+	PublicKey []byte
+}
+
+// AccessRequest is an operation to check. ObjectHeaders are the object's attributes and its $Object: fields, as
+// made by ObjectHeaders, and RequestHeaders are the X-Headers sent with it. A nil map means the request has no
+// headers of that kind available, which is not the same as having none.
+type AccessRequest struct {
+	Operation      eacl.Operation
+	ObjectHeaders  map[string]string
+	RequestHeaders map[string]string
+}
+
+// Decision is the outcome of Evaluate. Record is the eACL record that decided it, at Index in the table. When the
+// basic ACL decided, or no record matched, Record is nil and Index is -1.
+type Decision struct {
+	Allowed bool
+	Record  *eacl.Record
+	Index   int
+	Reason  string
+	//Undecided is set when a record filters on headers the request does not have. The network allows the request then,
+	//but whether it would with the headers depends on the object, so callers should not rely on it.
+	Undecided bool
+}
+
+func basicACLOp(op eacl.Operation) (acl.Op, bool) {
+	switch op {
+	case eacl.OperationGet:
+		return acl.OpObjectGet, true
+	case eacl.OperationHead:
+		return acl.OpObjectHead, true
+	case eacl.OperationPut:
+		return acl.OpObjectPut, true
+	case eacl.OperationDelete:
+		return acl.OpObjectDelete, true
+	case eacl.OperationSearch:
+		return acl.OpObjectSearch, true
+	case eacl.OperationRange:
+		return acl.OpObjectRange, true
+	case eacl.OperationRangeHash:
+		return acl.OpObjectHash, true
+	}
+	return 0, false
+}
+
+func basicACLRole(role eacl.Role) (acl.Role, bool) {
+	switch role {
+	case eacl.RoleUser:
+		return acl.RoleOwner, true
+	case eacl.RoleSystem:
+		return acl.RoleContainer, true
+	case eacl.RoleOthers:
+		return acl.RoleOthers, true
+	}
+	return 0, false
+}
+
+// Evaluate decides a request the way a storage node would. The basic ACL is checked first. If it allows the request
+// and can be extended, the records of table are checked in order and the first that matches the operation, the
+// requester and all of its filters decides. A request no record matches is allowed. table may be nil.
+func Evaluate(table *eacl.Table, basic acl.Basic, requester Requester, req AccessRequest) Decision {
+	op, ok := basicACLOp(req.Operation)
+	if !ok {
+		return Decision{Index: -1, Reason: "unknown operation " + req.Operation.String()}
+	}
+	role, ok := basicACLRole(requester.Role)
+	if !ok {
+		return Decision{Index: -1, Reason: "unknown role " + requester.Role.String()}
+	}
+	if !basic.IsOpAllowed(op, role) {
+		return Decision{Index: -1, Reason: "basic ACL denies " + op.String() + " to " + role.String()}
+	}
+	if !basic.Extendable() || table == nil {
+		return Decision{Allowed: true, Index: -1, Reason: "basic ACL allows " + op.String() + " to " + role.String()}
+	}
+	records := table.Records()
+	for i := range records {
+		record := records[i]
+		if record.Operation() != req.Operation || !eaclTargetMatches(record, requester) {
+			continue
+		}
+		switch unmatched := eaclFiltersUnmatched(record.Filters(), req); {
+		case unmatched < 0:
+			return Decision{Allowed: true, Index: -1, Undecided: true, Reason: "record " + strconv.Itoa(i) + " filters on headers the request does not have"}
+		case unmatched == 0:
+			allowed := record.Action() == eacl.ActionAllow
+			verb := " denies "
+			if allowed {
+				verb = " allows "
+			}
+			return Decision{
+				Allowed: allowed,
+				Record:  &record,
+				Index:   i,
+				Reason:  "record " + strconv.Itoa(i) + verb + op.String(),
+			}
+		}
+	}
+	return Decision{Allowed: true, Index: -1, Reason: "no eACL record matched"}
+}
+
+// eaclTargetMatches reports whether a record targets the requester, by key or by role. The system role can no longer
+// be targeted by eACL, so those targets are skipped as the network does.
+func eaclTargetMatches(record eacl.Record, requester Requester) bool {
+	for _, target := range record.Targets() {
+		if target.Role() == eacl.RoleSystem {
+			continue
+		}
+		if keys := target.BinaryKeys(); len(keys) != 0 {
+			for _, key := range keys {
+				if bytes.Equal(key, requester.PublicKey) {
+					return true
+				}
+			}
+			continue
+		}
+		if target.Role() == requester.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// eaclFiltersUnmatched returns how many filters the request fails, or -1 if it lacks the headers to check one.
+func eaclFiltersUnmatched(filters []eacl.Filter, req AccessRequest) int {
+	matched := 0
+	for _, filter := range filters {
+		var headers map[string]string
+		switch filter.From() {
+		case eacl.HeaderFromObject:
+			headers = req.ObjectHeaders
+		case eacl.HeaderFromRequest:
+			headers = req.RequestHeaders
+		}
+		if headers == nil {
+			return -1
+		}
+		value, present := headers[filter.Key()]
+		if eaclFilterMatches(filter, value, present) {
+			matched++
+		}
+	}
+	return len(filters) - matched
+}
+
+func eaclFilterMatches(filter eacl.Filter, value string, present bool) bool {
+	m := filter.Matcher()
+	if m == eacl.MatchNotPresent {
+		return !present
+	}
+	if !present {
+		return false
+	}
+	switch m {
+	case eacl.MatchStringEqual:
+		return value == filter.Value()
+	case eacl.MatchStringNotEqual:
+		return value != filter.Value()
+	case eacl.MatchNumGT, eacl.MatchNumGE, eacl.MatchNumLT, eacl.MatchNumLE:
+		//a value that is not a number never matches
+		var nv, nf big.Int
+		if _, ok := nf.SetString(filter.Value(), 10); !ok {
+			return false
+		}
+		if _, ok := nv.SetString(value, 10); !ok {
+			return false
+		}
+		cmp := nv.Cmp(&nf)
+		switch m {
+		case eacl.MatchNumGT:
+			return cmp > 0
+		case eacl.MatchNumGE:
+			return cmp >= 0
+		case eacl.MatchNumLT:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+	return false
+}
+
+// ObjectHeaders makes the headers eACL filters are checked against from an object header: its attributes and the
+// $Object: fields.
+func ObjectHeaders(hdr object.Object) map[string]string {
+	headers := make(map[string]string)
+	for _, a := range hdr.Attributes() {
+		headers[a.Key()] = a.Value()
+	}
+	if v := hdr.Version(); v != nil {
+		headers[eacl.FilterObjectVersion] = v.String()
+	}
+	if id, ok := hdr.ID(); ok {
+		headers[eacl.FilterObjectID] = id.String()
+	}
+	if cnrID, ok := hdr.ContainerID(); ok {
+		headers[eacl.FilterObjectContainerID] = cnrID.String()
+	}
+	if owner := hdr.OwnerID(); owner != nil {
+		headers[eacl.FilterObjectOwnerID] = owner.String()
+	}
+	headers[eacl.FilterObjectCreationEpoch] = strconv.FormatUint(hdr.CreationEpoch(), 10)
+	headers[eacl.FilterObjectPayloadSize] = strconv.FormatUint(hdr.PayloadSize(), 10)
+	headers[eacl.FilterObjectType] = hdr.Type().EncodeToString()
+	if cs, ok := hdr.PayloadChecksum(); ok {
+		headers[eacl.FilterObjectPayloadChecksum] = hex.EncodeToString(cs.Value())
+	}
+	if cs, ok := hdr.PayloadHomomorphicHash(); ok {
+		headers[eacl.FilterObjectPayloadHomomorphicChecksum] = hex.EncodeToString(cs.Value())
+	}
+	return headers
+}
@@ -0,0 +1,123 @@
+package container
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+func TestEvaluate(t *testing.T) {
+	friend := testPublicKeyHex(t)
+	friendKey, _ := hex.DecodeString(friend)
+	stranger, _ := hex.DecodeString(testPublicKeyHex(t))
+
+	publicFiles, err := ParseEACL("", `
+allow get,head to others where $Object:Public == "yes"
+deny get,head to others
+allow put to key:`+friend+`
+deny put,delete to others
+deny get to user where $Object:payloadLength > 1000
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := map[string]string{"Public": "yes"}
+	private := map[string]string{"Public": "no"}
+
+	var hdr object.Object
+	hdr.SetPayloadSize(5000)
+	big := ObjectHeaders(hdr)
+
+	cases := []struct {
+		name      string
+		table     *eacl.Table
+		basic     acl.Basic
+		requester Requester
+		req       AccessRequest
+		allowed   bool
+		index     int
+	}{
+		{"public object", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet, ObjectHeaders: public}, true, 0},
+		{"private object falls to deny", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationHead, ObjectHeaders: private}, false, 3},
+		{"no attribute falls to deny", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet, ObjectHeaders: map[string]string{}}, false, 2},
+		{"no object headers allows", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet}, true, -1},
+		{"friend may put", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers, PublicKey: friendKey}, AccessRequest{Operation: eacl.OperationPut}, true, 4},
+		{"stranger may not put", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers, PublicKey: stranger}, AccessRequest{Operation: eacl.OperationPut}, false, 5},
+		{"others may search", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationSearch}, true, -1},
+		{"owner large object", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleUser}, AccessRequest{Operation: eacl.OperationGet, ObjectHeaders: big}, false, 7},
+		{"owner small object", publicFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleUser}, AccessRequest{Operation: eacl.OperationGet, ObjectHeaders: map[string]string{eacl.FilterObjectPayloadSize: "10"}}, true, -1},
+		{"basic ACL denies first", publicFiles, acl.PrivateExtended, Requester{Role: eacl.RoleOthers, PublicKey: friendKey}, AccessRequest{Operation: eacl.OperationPut}, false, -1},
+		{"final basic ACL ignores the table", publicFiles, acl.PublicRW, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationPut}, true, -1},
+		{"no table", nil, acl.PublicRO, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet}, true, -1},
+		{"read only", nil, acl.PublicRO, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationPut}, false, -1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Evaluate(c.table, c.basic, c.requester, c.req)
+			if d.Allowed != c.allowed {
+				t.Errorf("expected allowed %v, got %v (%s)", c.allowed, d.Allowed, d.Reason)
+			}
+			if d.Index != c.index {
+				t.Errorf("expected record %d, got %d (%s)", c.index, d.Index, d.Reason)
+			}
+			if (d.Record != nil) != (c.index >= 0) {
+				t.Errorf("record %v does not agree with index %d", d.Record, d.Index)
+			}
+		})
+	}
+}
+
+func TestEvaluateWithoutHeadersIsUndecided(t *testing.T) {
+	privateFiles, err := ParseEACL("", `deny get to others where $Object:Private == "yes"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Evaluate(privateFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet})
+	if !d.Undecided {
+		t.Errorf("a filtered deny cannot be ruled out without the headers (%s)", d.Reason)
+	}
+	d = Evaluate(privateFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationGet, ObjectHeaders: map[string]string{"Private": "no"}})
+	if !d.Allowed || d.Undecided {
+		t.Errorf("with the headers the request is decided (%s)", d.Reason)
+	}
+	d = Evaluate(privateFiles, acl.PublicRWExtended, Requester{Role: eacl.RoleOthers}, AccessRequest{Operation: eacl.OperationPut})
+	if !d.Allowed || d.Undecided {
+		t.Errorf("no record is about put (%s)", d.Reason)
+	}
+}
+
+func TestEvaluateMatchers(t *testing.T) {
+	cases := []struct {
+		matcher eacl.Match
+		filter  string
+		value   string
+		present bool
+		want    bool
+	}{
+		{eacl.MatchStringEqual, "a", "a", true, true},
+		{eacl.MatchStringEqual, "a", "b", true, false},
+		{eacl.MatchStringEqual, "a", "", false, false},
+		{eacl.MatchStringNotEqual, "a", "b", true, true},
+		{eacl.MatchStringNotEqual, "a", "", false, false},
+		{eacl.MatchNotPresent, "", "", false, true},
+		{eacl.MatchNotPresent, "", "a", true, false},
+		{eacl.MatchNumGT, "10", "11", true, true},
+		{eacl.MatchNumGT, "10", "10", true, false},
+		{eacl.MatchNumGE, "10", "10", true, true},
+		{eacl.MatchNumLT, "10", "9", true, true},
+		{eacl.MatchNumLE, "10", "11", true, false},
+		{eacl.MatchNumGT, "10", "eleven", true, false},
+		{eacl.MatchNumLT, "ten", "9", true, false},
+		{eacl.MatchNumGT, "18446744073709551615", "18446744073709551616", true, true},
+	}
+	for _, c := range cases {
+		var r eacl.Record
+		r.AddObjectAttributeFilter(c.matcher, "k", c.filter)
+		if got := eaclFilterMatches(r.Filters()[0], c.value, c.present); got != c.want {
+			t.Errorf("%s %q against %q (present %v): expected %v, got %v", c.matcher, c.filter, c.value, c.present, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/invoker"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+// DefaultDomainZone is the NNS zone containers are registered in when no other zone is given.
+const DefaultDomainZone = "container"
+
+const (
+	//the NNS contract is always the first one deployed on the NeoFS sidechain
+	nnsContractID = 1
+	//TXT records hold the container ID
+	nnsRecordTXT = 16
+)
+
+// NNSResolver looks container names up in NeoFS NNS.
+type NNSResolver interface {
+	// Resolve returns the TXT records of a fully qualified name, e.g. photos.container.
+	Resolve(name string) ([]string, error)
+	// IsAvailable reports whether a fully qualified name can still be registered.
+	IsAvailable(name string) (bool, error)
+}
+
+// NNSClient resolves names through the NNS contract on the NeoFS sidechain.
+type NNSClient struct {
+	inv  *invoker.Invoker
+	hash util.Uint160
+}
+
+// DialNNS connects to a sidechain RPC endpoint (see utils.NetworkData.SidechainRPC) and finds the NNS contract.
+func DialNNS(ctx context.Context, endpoint string) (*NNSClient, error) {
+	cli, err := rpcclient.New(ctx, endpoint, rpcclient.Options{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.Init(); err != nil {
+		return nil, err
+	}
+	contract, err := cli.GetContractStateByID(nnsContractID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the NNS contract: %w", err)
+	}
+	return &NNSClient{inv: invoker.New(cli, nil), hash: contract.Hash}, nil
+}
+
+func (n *NNSClient) Resolve(name string) ([]string, error) {
+	return unwrap.ArrayOfUTF8Strings(n.inv.Call(n.hash, "resolve", name, int64(nnsRecordTXT)))
+}
+
+func (n *NNSClient) IsAvailable(name string) (bool, error) {
+	return unwrap.Bool(n.inv.Call(n.hash, "isAvailable", name))
+}
+
+// MemoryNNS is an NNSResolver kept in memory, a stand-in for the network when testing or working offline.
+type MemoryNNS struct {
+	mu      sync.RWMutex
+	records map[string][]string
+}
+
+func NewMemoryNNS() *MemoryNNS {
+	return &MemoryNNS{records: make(map[string][]string)}
+}
+
+// Register adds a TXT record for name, as the container contract does when a named container is created.
+func (m *MemoryNNS) Register(name, record string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = strings.ToLower(name)
+	m.records[name] = append(m.records[name], record)
+}
+
+func (m *MemoryNNS) Resolve(name string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	records, ok := m.records[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New("name " + name + " is not registered")
+	}
+	return records, nil
+}
+
+func (m *MemoryNNS) IsAvailable(name string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.records[strings.ToLower(name)]
+	return !ok, nil
+}
+
+// SplitDomain splits a container name into its name and zone. A name without a zone is in DefaultDomainZone.
+func SplitDomain(domain string) (name, zone string) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "."); i >= 0 {
+		return domain[:i], domain[i+1:]
+	}
+	return domain, DefaultDomainZone
+}
+
+// ResolveContainerID decodes id as a container ID, or returns the one registered in NNS if id is a name such as
+// photos.container (or just photos, in DefaultDomainZone).
+func ResolveContainerID(nns NNSResolver, id string) (cid.ID, error) {
+	var cnrId cid.ID
+	decodeErr := cnrId.DecodeString(id)
+	if decodeErr == nil {
+		return cnrId, nil
+	}
+	if nns == nil {
+		return cnrId, decodeErr
+	}
+	name, zone := SplitDomain(id)
+	if name == "" {
+		return cnrId, decodeErr
+	}
+	records, err := nns.Resolve(name + "." + zone)
+	if err != nil {
+		return cnrId, fmt.Errorf("could not resolve %s.%s: %w", name, zone, err)
+	}
+	for _, record := range records {
+		if err := cnrId.DecodeString(record); err == nil {
+			return cnrId, nil
+		}
+	}
+	return cnrId, errors.New("no container is registered as " + name + "." + zone)
+}
+
+// containerID resolves p.Id using the NNS for p, if there is one.
+func (o *ContainerCaller) containerID(p ContainerParameter) (cid.ID, error) {
+	return ResolveContainerID(o.nns(p), p.Id)
+}
+
+// nns is the resolver for p: its own, or the caller's.
+func (o *ContainerCaller) nns(p ContainerParameter) NNSResolver {
+	if p.NNS != nil {
+		return p.NNS
+	}
+	return o.NNS
+}
+
+// registerDomain checks p.Domain can be registered and returns it with the fee for registering it.
+func (o *ContainerCaller) registerDomain(ctx context.Context, p ContainerParameter) (container.Domain, uint64, error) {
+	var d container.Domain
+	name, zone := SplitDomain(p.Domain)
+	if name == "" || zone == "" || strings.Contains(zone, ".") {
+		return d, 0, errors.New("invalid container name " + p.Domain)
+	}
+	d.SetName(name)
+	d.SetZone(zone)
+	if nns := o.nns(p); nns != nil {
+		available, err := nns.IsAvailable(name + "." + zone)
+		if err != nil {
+			return d, 0, err
+		}
+		if !available {
+			return d, 0, errors.New(name + "." + zone + " is already registered")
+		}
+	}
+	fee := p.NamedContainerFee
+	if fee == 0 && p.Pl != nil {
+		ni, err := p.Pl.NetworkInfo(ctx, client.PrmNetworkInfo{})
+		if err != nil {
+			return d, 0, err
+		}
+		fee = ni.NamedContainerFee()
+	}
+	return d, fee, nil
+}
@@ -0,0 +1,74 @@
+package container
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolveContainerID(t *testing.T) {
+	id := testContainerID()
+	nns := NewMemoryNNS()
+	nns.Register("photos.container", "not a container id")
+	nns.Register("photos.container", id)
+	nns.Register("backups.team", id)
+
+	for _, name := range []string{id, "photos", "Photos.container", "backups.team"} {
+		got, err := ResolveContainerID(nns, name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if got.String() != id {
+			t.Errorf("%s resolved to %s", name, got)
+		}
+	}
+	for _, name := range []string{"videos", "backups", ""} {
+		if _, err := ResolveContainerID(nns, name); err == nil {
+			t.Errorf("expected %q not to resolve", name)
+		}
+	}
+	if _, err := ResolveContainerID(nil, "photos"); err == nil {
+		t.Error("expected names not to resolve without NNS")
+	}
+	//IDs never need NNS
+	if got, err := ResolveContainerID(nil, id); err != nil || got.String() != id {
+		t.Errorf("expected %s, got %s (%v)", id, got, err)
+	}
+}
+
+func TestRegisterDomain(t *testing.T) {
+	nns := NewMemoryNNS()
+	nns.Register("photos.container", testContainerID())
+	caller := ContainerCaller{NNS: nns}
+
+	d, fee, err := caller.registerDomain(context.Background(), ContainerParameter{Domain: "Videos", NamedContainerFee: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name() != "videos" || d.Zone() != DefaultDomainZone || fee != 1000 {
+		t.Errorf("unexpected domain %s.%s costing %d", d.Name(), d.Zone(), fee)
+	}
+	d, _, err = caller.registerDomain(context.Background(), ContainerParameter{Domain: "videos.team", NamedContainerFee: 1})
+	if err != nil || d.Zone() != "team" {
+		t.Errorf("expected the team zone, got %s (%v)", d.Zone(), err)
+	}
+	for _, domain := range []string{"photos", "photos.container", ".container", "a.b.c"} {
+		if _, _, err := caller.registerDomain(context.Background(), ContainerParameter{Domain: domain, NamedContainerFee: 1}); err == nil {
+			t.Errorf("expected %q to be refused", domain)
+		}
+	}
+}
+
+func TestParameterNNS(t *testing.T) {
+	nns := NewMemoryNNS()
+	nns.Register("photos.container", testContainerID())
+	var caller ContainerCaller
+	p := ContainerParameter{Id: "photos", Domain: "photos", NamedContainerFee: 1, NNS: nns}
+
+	if id, err := caller.containerID(p); err != nil || id.String() != testContainerID() {
+		t.Errorf("expected the name to resolve with the parameter's NNS, got %s (%v)", id, err)
+	}
+	if _, _, err := caller.registerDomain(context.Background(), p); err == nil {
+		t.Error("expected the parameter's NNS to find the name taken")
+	}
+}
@@ -0,0 +1,207 @@
+package container
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPlacementPolicy is used when a ContainerParameter carries no policy.
+const DefaultPlacementPolicy = "REP 3"
+
+// PlacementPolicy describes where a container's objects are stored. Either QL is set to a policy in the NeoFS query
+// language, e.g. `REP 2 IN X CBF 2 SELECT 2 FROM * AS X`, or the structured fields are, which are rendered into QL.
+type PlacementPolicy struct {
+	QL           string       `json:"ql,omitempty"`
+	Replicas     []Replica    `json:"replicas,omitempty"`
+	BackupFactor uint32       `json:"backupFactor,omitempty"`
+	Selectors    []Selector   `json:"selectors,omitempty"`
+	Filters      []NodeFilter `json:"filters,omitempty"`
+}
+
+// Replica is a REP statement: Count copies of each object on the nodes picked by Selector, or by any node if empty.
+type Replica struct {
+	Count    uint32 `json:"count"`
+	Selector string `json:"selector,omitempty"`
+}
+
+// Selector is a SELECT statement. Count nodes are picked from those passing Filter ("*" or empty for all nodes),
+// grouped by Attribute with Clause "SAME" or "DISTINCT".
+type Selector struct {
+	Name      string `json:"name"`
+	Count     uint32 `json:"count"`
+	Clause    string `json:"clause,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Filter    string `json:"filter,omitempty"`
+}
+
+// NodeFilter is a FILTER on node attributes. A simple filter compares Key to Value with Op (EQ, NE, GT, GE, LT, LE).
+// With Op AND or OR it combines its Filters instead. Ref refers to another named filter in place of a comparison.
+type NodeFilter struct {
+	Name    string       `json:"name,omitempty"`
+	Key     string       `json:"key,omitempty"`
+	Op      string       `json:"op,omitempty"`
+	Value   string       `json:"value,omitempty"`
+	Ref     string       `json:"ref,omitempty"`
+	Filters []NodeFilter `json:"filters,omitempty"`
+}
+
+// ResidentIn keeps replicas copies of every object on nodes whose attribute key is one of values,
+// e.g. ResidentIn(2, "Country", "Germany", "France").
+func ResidentIn(replicas uint32, key string, values ...string) PlacementPolicy {
+	filter := NodeFilter{Name: "RESIDENT", Op: "OR"}
+	for _, v := range values {
+		filter.Filters = append(filter.Filters, NodeFilter{Key: key, Op: "EQ", Value: v})
+	}
+	if len(values) == 1 {
+		filter = NodeFilter{Name: "RESIDENT", Key: key, Op: "EQ", Value: values[0]}
+	}
+	return PlacementPolicy{
+		Replicas:  []Replica{{Count: replicas, Selector: "NODES"}},
+		Selectors: []Selector{{Name: "NODES", Count: replicas, Filter: "RESIDENT"}},
+		Filters:   []NodeFilter{filter},
+	}
+}
+
+// EUOnly keeps every copy of a container's objects on nodes in Europe.
+func EUOnly(replicas uint32) PlacementPolicy {
+	return ResidentIn(replicas, "Continent", "Europe")
+}
+
+var qlIdent = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func quoteIfNeeded(s string) string {
+	if qlIdent.MatchString(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func (f NodeFilter) expression() (string, error) {
+	if f.Ref != "" {
+		return "@" + f.Ref, nil
+	}
+	op := strings.ToUpper(f.Op)
+	switch op {
+	case "AND", "OR":
+		if len(f.Filters) < 2 {
+			return "", fmt.Errorf("filter %s needs at least two filters to %s", f.Name, op)
+		}
+		var parts []string
+		for _, inner := range f.Filters {
+			expr, err := inner.expression()
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, expr)
+		}
+		return "(" + strings.Join(parts, " "+op+" ") + ")", nil
+	case "EQ", "NE", "GT", "GE", "LT", "LE":
+		if f.Key == "" {
+			return "", errors.New("filter has no key")
+		}
+		return quoteIfNeeded(f.Key) + " " + op + " " + strconv.Quote(f.Value), nil
+	default:
+		return "", fmt.Errorf("unknown filter operation %q", f.Op)
+	}
+}
+
+// String renders the policy in the query language. A policy with QL set is returned as it is.
+func (p PlacementPolicy) String() string {
+	ql, err := p.render()
+	if err != nil {
+		return ""
+	}
+	return ql
+}
+
+func (p PlacementPolicy) render() (string, error) {
+	if p.QL != "" {
+		return p.QL, nil
+	}
+	if len(p.Replicas) == 0 {
+		return "", errors.New("placement policy needs at least one replica")
+	}
+	var b strings.Builder
+	for _, r := range p.Replicas {
+		if r.Count == 0 {
+			return "", errors.New("replica count must be at least 1")
+		}
+		fmt.Fprintf(&b, "REP %d", r.Count)
+		if r.Selector != "" {
+			b.WriteString(" IN " + r.Selector)
+		}
+		b.WriteString(" ")
+	}
+	if p.BackupFactor > 0 {
+		fmt.Fprintf(&b, "CBF %d ", p.BackupFactor)
+	}
+	for _, s := range p.Selectors {
+		if s.Count == 0 {
+			return "", fmt.Errorf("selector %s must select at least 1 node", s.Name)
+		}
+		fmt.Fprintf(&b, "SELECT %d", s.Count)
+		if s.Attribute != "" {
+			b.WriteString(" IN ")
+			if s.Clause != "" {
+				b.WriteString(strings.ToUpper(s.Clause) + " ")
+			}
+			b.WriteString(s.Attribute)
+		}
+		from := s.Filter
+		if from == "" {
+			from = "*"
+		}
+		b.WriteString(" FROM " + from)
+		if s.Name != "" {
+			b.WriteString(" AS " + s.Name)
+		}
+		b.WriteString(" ")
+	}
+	for _, f := range p.Filters {
+		if f.Name == "" {
+			return "", errors.New("top level filters must be named")
+		}
+		expr, err := f.expression()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("FILTER " + expr + " AS " + f.Name + " ")
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Build parses the policy into the SDK's form, using DefaultPlacementPolicy for an empty policy.
+func (p PlacementPolicy) Build() (netmap.PlacementPolicy, error) {
+	var policy netmap.PlacementPolicy
+	if p.QL == "" && len(p.Replicas) == 0 {
+		p.QL = DefaultPlacementPolicy
+	}
+	ql, err := p.render()
+	if err != nil {
+		return policy, err
+	}
+	if err := policy.DecodeString(ql); err != nil {
+		return policy, fmt.Errorf("invalid placement policy %q: %w", ql, err)
+	}
+	return policy, nil
+}
+
+// ValidatePolicy checks that the netmap has enough nodes to satisfy the policy, so a container is not created
+// that can never hold its objects as asked.
+func ValidatePolicy(policy netmap.PlacementPolicy, nm netmap.NetMap) error {
+	if len(nm.Nodes()) == 0 {
+		return errors.New("netmap has no nodes")
+	}
+	//which nodes are picked depends on the container id, whether enough exist does not
+	cnrID := cid.ID(sha256.Sum256([]byte("placement validation")))
+	if _, err := nm.ContainerNodes(policy, cnrID); err != nil {
+		return fmt.Errorf("placement policy cannot be satisfied by the current network: %w", err)
+	}
+	return nil
+}
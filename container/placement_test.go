@@ -0,0 +1,80 @@
+package container
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+)
+
+func testNetmap(continents ...string) netmap.NetMap {
+	var nodes []netmap.NodeInfo
+	for i, continent := range continents {
+		var n netmap.NodeInfo
+		key := make([]byte, 33)
+		rand.Read(key)
+		n.SetPublicKey(key)
+		n.SetNetworkEndpoints(fmt.Sprintf("/dns4/node%d.example/tcp/8080", i))
+		n.SetContinentName(continent)
+		n.SetCountryName("Somewhere")
+		nodes = append(nodes, n)
+	}
+	var nm netmap.NetMap
+	nm.SetNodes(nodes)
+	return nm
+}
+
+func TestPlacementPolicyBuild(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy PlacementPolicy
+		want   string
+	}{
+		{"default", PlacementPolicy{}, "REP 3"},
+		{"raw", PlacementPolicy{QL: "REP 2 IN X CBF 2 SELECT 2 FROM * AS X"}, "REP 2 IN X CBF 2 SELECT 2 FROM * AS X"},
+		{"eu", EUOnly(2), `REP 2 IN NODES SELECT 2 FROM RESIDENT AS NODES FILTER Continent EQ "Europe" AS RESIDENT`},
+		{"countries", ResidentIn(1, "Country", "Germany", "United Kingdom"), `REP 1 IN NODES SELECT 1 FROM RESIDENT AS NODES FILTER (Country EQ "Germany" OR Country EQ "United Kingdom") AS RESIDENT`},
+		{"distinct", PlacementPolicy{
+			Replicas:     []Replica{{Count: 3, Selector: "SPREAD"}},
+			BackupFactor: 2,
+			Selectors:    []Selector{{Name: "SPREAD", Count: 3, Clause: "distinct", Attribute: "Country"}},
+		}, "REP 3 IN SPREAD CBF 2 SELECT 3 IN DISTINCT Country FROM * AS SPREAD"},
+	}
+	for _, c := range cases {
+		if c.name != "default" && c.policy.String() != c.want {
+			t.Errorf("%s: rendered %q, want %q", c.name, c.policy.String(), c.want)
+		}
+		if _, err := c.policy.Build(); err != nil {
+			t.Errorf("%s: %s", c.name, err)
+		}
+	}
+
+	invalid := []PlacementPolicy{
+		{QL: "REP"},
+		{Replicas: []Replica{{Count: 0}}},
+		{Replicas: []Replica{{Count: 1}}, Filters: []NodeFilter{{Key: "Country", Op: "EQ", Value: "DE"}}},
+		{Replicas: []Replica{{Count: 1}}, Filters: []NodeFilter{{Name: "F", Key: "Country", Op: "LIKE", Value: "DE"}}},
+	}
+	for _, policy := range invalid {
+		if _, err := policy.Build(); err == nil {
+			t.Errorf("expected %+v to be rejected", policy)
+		}
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	eu, err := EUOnly(2).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidatePolicy(eu, testNetmap("Europe", "Europe", "Asia")); err != nil {
+		t.Errorf("two european nodes should satisfy the policy: %s", err)
+	}
+	if err := ValidatePolicy(eu, testNetmap("Europe", "Asia", "Asia")); err == nil {
+		t.Error("one european node should not satisfy a policy for two")
+	}
+	if err := ValidatePolicy(eu, netmap.NetMap{}); err == nil {
+		t.Error("an empty netmap should not satisfy anything")
+	}
+}
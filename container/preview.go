@@ -0,0 +1,81 @@
+package container
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// PlacementNode is a storage node chosen by a placement policy.
+type PlacementNode struct {
+	PublicKey  string            `json:"publicKey"`
+	Addresses  []string          `json:"addresses"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// NetmapSnapshot is a netmap that can be saved as JSON and previewed against later without a network.
+type NetmapSnapshot struct {
+	Epoch uint64            `json:"epoch"`
+	Nodes []netmap.NodeInfo `json:"nodes"`
+}
+
+func SnapshotFromNetmap(nm netmap.NetMap) NetmapSnapshot {
+	return NetmapSnapshot{Epoch: nm.Epoch(), Nodes: nm.Nodes()}
+}
+
+func (s NetmapSnapshot) NetMap() netmap.NetMap {
+	var nm netmap.NetMap
+	nm.SetEpoch(s.Epoch)
+	nm.SetNodes(s.Nodes)
+	return nm
+}
+
+// FetchNetmapSnapshot retrieves the current netmap from the network.
+func FetchNetmapSnapshot(ctx context.Context, pl *pool.Pool) (NetmapSnapshot, error) {
+	nm, err := pl.NetMapSnapshot(ctx, client.PrmNetMapSnapshot{})
+	if err != nil {
+		return NetmapSnapshot{}, err
+	}
+	return SnapshotFromNetmap(nm), nil
+}
+
+// PreviewPlacement returns the nodes the policy would choose in nm for a container, one vector per replica.
+// With an object ID the vectors are ordered as they would be for that object, the first nodes in each vector
+// being the ones that would hold it.
+func PreviewPlacement(policy PlacementPolicy, nm netmap.NetMap, cnrID cid.ID, objID *oid.ID) ([][]PlacementNode, error) {
+	built, err := policy.Build()
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := nm.ContainerNodes(built, cnrID)
+	if err != nil {
+		return nil, err
+	}
+	if objID != nil {
+		if vectors, err = nm.PlacementVectors(vectors, *objID); err != nil {
+			return nil, err
+		}
+	}
+	preview := make([][]PlacementNode, len(vectors))
+	for i, vector := range vectors {
+		for _, node := range vector {
+			placementNode := PlacementNode{
+				PublicKey:  hex.EncodeToString(node.PublicKey()),
+				Attributes: make(map[string]string),
+			}
+			node.IterateNetworkEndpoints(func(address string) bool {
+				placementNode.Addresses = append(placementNode.Addresses, address)
+				return false
+			})
+			node.IterateAttributes(func(key, value string) {
+				placementNode.Attributes[key] = value
+			})
+			preview[i] = append(preview[i], placementNode)
+		}
+	}
+	return preview, nil
+}
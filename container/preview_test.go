@@ -0,0 +1,77 @@
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+func TestPreviewPlacement(t *testing.T) {
+	nm := testNetmap("Europe", "Europe", "Europe", "Asia", "Africa")
+	cnrID := cid.ID(sha256.Sum256([]byte("container")))
+
+	preview, err := PreviewPlacement(EUOnly(2), nm, cnrID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the backup factor widens the vector beyond the two replicas, but only with european nodes
+	if len(preview) != 1 || len(preview[0]) < 2 {
+		t.Fatalf("expected one vector of at least two nodes, got %+v", preview)
+	}
+	for _, node := range preview[0] {
+		if node.Attributes["Continent"] != "Europe" {
+			t.Errorf("node %s is outside Europe", node.PublicKey)
+		}
+		if len(node.Addresses) != 1 || !strings.HasPrefix(node.Addresses[0], "/dns4/") {
+			t.Errorf("node %s has unexpected addresses %v", node.PublicKey, node.Addresses)
+		}
+	}
+
+	//the same inputs always give the same placement
+	again, _ := PreviewPlacement(EUOnly(2), nm, cnrID, nil)
+	if again[0][0].PublicKey != preview[0][0].PublicKey || again[0][1].PublicKey != preview[0][1].PublicKey {
+		t.Error("placement is not deterministic")
+	}
+
+	objID := oid.ID(sha256.Sum256([]byte("object")))
+	objectPreview, err := PreviewPlacement(PlacementPolicy{QL: "REP 1 SELECT 3 FROM *"}, nm, cnrID, &objID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objectPreview) != 1 || len(objectPreview[0]) < 3 {
+		t.Fatalf("expected the selected nodes ordered for the object, got %+v", objectPreview)
+	}
+
+	if _, err := PreviewPlacement(EUOnly(4), nm, cnrID, nil); err == nil {
+		t.Error("a policy needing four european nodes should fail with three")
+	}
+}
+
+func TestNetmapSnapshotJSON(t *testing.T) {
+	nm := testNetmap("Europe", "Asia", "Europe")
+	nm.SetEpoch(42)
+	data, err := json.Marshal(SnapshotFromNetmap(nm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved NetmapSnapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	cnrID := cid.ID(sha256.Sum256([]byte("container")))
+	want, err := PreviewPlacement(EUOnly(2), nm, cnrID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PreviewPlacement(EUOnly(2), saved.NetMap(), cnrID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Epoch != 42 || got[0][0].PublicKey != want[0][0].PublicKey || got[0][1].PublicKey != want[0][1].PublicKey {
+		t.Error("a saved snapshot should preview the same as the live netmap")
+	}
+}
@@ -0,0 +1,313 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/database"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const gibibyte = 1 << 30
+
+// ContainerUsage is how much a container stores. Objects counts what was uploaded: regular objects that are not part of
+// a split object. Bytes counts the payload of every physical object once, so the parts of a large object are counted
+// and its parent is not, as are link, lock and tombstone objects. Replicas is how many copies the placement policy keeps
+// of each object.
+type ContainerUsage struct {
+	ContainerID string `json:"containerID"`
+	Objects     int    `json:"objects"`
+	Bytes       uint64 `json:"bytes"`
+	Replicas    uint32 `json:"replicas"`
+	Epoch       uint64 `json:"epoch"`
+	UpdatedAt   int64  `json:"updatedAt"`
+	//ObjectSizes is what was counted of every object, so a refresh only needs to head new objects. It is stored apart
+	//from the totals, see cacheObjectSizes, as a container's list of objects grows without bound.
+	ObjectSizes map[string]ObjectUsage `json:"-"`
+}
+
+// ObjectUsage is what Usage counts of an object.
+type ObjectUsage struct {
+	Size uint64 `json:"size"`
+	Root bool   `json:"root,omitempty"` //counted in Objects
+}
+
+// objectUsage reads what Usage counts from the head of a physical object. A regular object that is not part of a split
+// object is a root, and a split object counts once through its first part: the only part with neither a first nor a
+// previous part. Link objects, which list the parts, are not roots.
+func objectUsage(hdr *object.Object) ObjectUsage {
+	_, hasFirst := hdr.FirstID()
+	_, hasPrevious := hdr.PreviousID()
+	root := hdr.Type() == object.TypeRegular && !hasFirst && !hasPrevious && len(hdr.Children()) == 0
+	return ObjectUsage{Size: hdr.PayloadSize(), Root: root}
+}
+
+// StoredBytes is the bytes held across every replica.
+func (u ContainerUsage) StoredBytes() uint64 {
+	replicas := u.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	return u.Bytes * uint64(replicas)
+}
+
+// EstimatedCost is the cost of storing the container for one epoch, in the units of storagePrice, which the network
+// charges per GiB per epoch (NetworkFees.StoragePrice). Part of a GiB is charged in proportion.
+func (u ContainerUsage) EstimatedCost(storagePrice uint64) uint64 {
+	cost := new(big.Int).SetUint64(u.StoredBytes())
+	cost.Mul(cost, new(big.Int).SetUint64(storagePrice))
+	cost.Div(cost, big.NewInt(gibibyte))
+	if !cost.IsUint64() {
+		return ^uint64(0)
+	}
+	return cost.Uint64()
+}
+
+// apply updates the usage to the objects now in the container, heading only those it has not seen before.
+func (u *ContainerUsage) apply(ids []string, head func(id string) (ObjectUsage, error)) error {
+	sizes := make(map[string]ObjectUsage, len(ids))
+	for _, id := range ids {
+		if size, ok := u.ObjectSizes[id]; ok {
+			sizes[id] = size
+			continue
+		}
+		size, err := head(id)
+		if err != nil {
+			return err
+		}
+		sizes[id] = size
+	}
+	u.ObjectSizes = sizes
+	u.Objects = 0
+	u.Bytes = 0
+	for _, size := range sizes {
+		if size.Root {
+			u.Objects++
+		}
+		u.Bytes += size.Size
+	}
+	u.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// cachedUsage returns the usage saved by the last refresh, or an empty usage if there is none.
+func cachedUsage(store database.Store, containerID string) ContainerUsage {
+	usage := ContainerUsage{ContainerID: containerID}
+	if store == nil {
+		return usage
+	}
+	byt, err := store.Select(database.UsageBucket, containerID)
+	if err != nil {
+		return usage
+	}
+	if err := json.Unmarshal(byt, &usage); err != nil {
+		fmt.Println("could not read cached usage ", containerID, err)
+		return ContainerUsage{ContainerID: containerID}
+	}
+	return usage
+}
+
+func cacheUsage(store database.Store, usage ContainerUsage) error {
+	if store == nil {
+		return nil
+	}
+	byt, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	return store.Update(database.UsageBucket, usage.ContainerID, byt)
+}
+
+// objectSizesID is where the ObjectSizes of a container are kept in the usage bucket, next to its totals.
+func objectSizesID(containerID string) string {
+	return containerID + "/objects"
+}
+
+// cachedObjectSizes returns the ObjectSizes saved by the last refresh, nil if there are none.
+func cachedObjectSizes(store database.Store, containerID string) map[string]ObjectUsage {
+	if store == nil {
+		return nil
+	}
+	byt, err := store.Select(database.UsageBucket, objectSizesID(containerID))
+	if err != nil {
+		return nil
+	}
+	var sizes map[string]ObjectUsage
+	if err := json.Unmarshal(byt, &sizes); err != nil {
+		fmt.Println("could not read cached object sizes ", containerID, err)
+		return nil
+	}
+	return sizes
+}
+
+// cacheObjectSizes saves the ObjectSizes of a usage so the next refresh, after a restart too, only heads new objects.
+func cacheObjectSizes(store database.Store, usage ContainerUsage) error {
+	if store == nil {
+		return nil
+	}
+	byt, err := json.Marshal(usage.ObjectSizes)
+	if err != nil {
+		return err
+	}
+	return store.Update(database.UsageBucket, objectSizesID(usage.ContainerID), byt)
+}
+
+// CachedUsage returns the usage of a container as it was last refreshed, without going to the network.
+func (o *ContainerCaller) CachedUsage(containerID string) (ContainerUsage, error) {
+	usage := cachedUsage(o.Store, containerID)
+	if usage.UpdatedAt == 0 {
+		return usage, errors.New("no usage recorded for container " + containerID)
+	}
+	return usage, nil
+}
+
+// Usage counts the objects in the container of p and their total payload size. The totals are cached, and so is what
+// was counted of each object, which is not headed again, so refreshing a large container only costs a search and the
+// heads of new objects.
+// The size estimations storage nodes announce are kept in the container contract, which the storage API does not
+// expose, so usage is counted from the objects themselves.
+// The usage is emitted as a ContainerUsageUpdate, and the container with its Size as a ContainerAddUpdate if it is cached.
+func (o *ContainerCaller) Usage(ctx context.Context, p ContainerParameter, token tokens.Token) (ContainerUsage, error) {
+	cnrId, err := o.containerID(p)
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	p.Id = cnrId.String()
+	gA, err := p.ForUser()
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	prmSearch := client.PrmObjectSearch{}
+	prmHead := client.PrmObjectHead{}
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return ContainerUsage{}, errors.New("no bearer token provided")
+			} else {
+				prmSearch.WithBearerToken(*tok.BearerToken)
+				prmHead.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			prmSearch.WithBearerToken(*tok.BearerToken)
+			prmHead.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	remoteContainer, err := p.Pl.ContainerGet(ctx, cnrId, client.PrmContainerGet{})
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	ni, err := p.Pl.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+
+	//physical objects only, the parts of large objects are what is actually stored
+	filters := object.NewSearchFilters()
+	filters.AddPhyFilter()
+	prmSearch.SetFilters(filters)
+	init, err := p.Pl.ObjectSearchInit(ctx, cnrId, gateSigner, prmSearch)
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	var ids []string
+	if err := init.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id.String())
+		return false
+	}); err != nil {
+		return ContainerUsage{}, err
+	}
+
+	usage := cachedUsage(o.Store, p.Id)
+	usage.ContainerID = p.Id
+	usage.ObjectSizes = cachedObjectSizes(o.Store, p.Id)
+	usage.Epoch = ni.CurrentEpoch()
+	usage.Replicas = 0
+	policy := remoteContainer.PlacementPolicy()
+	for i := 0; i < policy.NumberOfReplicas(); i++ {
+		usage.Replicas += policy.ReplicaNumberByIndex(i)
+	}
+	if err := usage.apply(ids, func(id string) (ObjectUsage, error) {
+		var objID oid.ID
+		if err := objID.DecodeString(id); err != nil {
+			return ObjectUsage{}, err
+		}
+		hdr, err := p.Pl.ObjectHead(ctx, cnrId, objID, gateSigner, prmHead)
+		if err != nil {
+			return ObjectUsage{}, fmt.Errorf("head %s: %w", id, err)
+		}
+		return objectUsage(hdr), nil
+	}); err != nil {
+		return ContainerUsage{}, err
+	}
+	if err := cacheUsage(o.Store, usage); err != nil {
+		fmt.Println("could not cache usage ", p.Id, err)
+	}
+	if err := cacheObjectSizes(o.Store, usage); err != nil {
+		fmt.Println("could not cache object sizes ", p.Id, err)
+	}
+	if p.ContainerEmitter != nil {
+		summary := usage
+		summary.ObjectSizes = nil
+		if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerUsageUpdate, summary); err != nil {
+			fmt.Println("could not emit usage", err)
+		}
+		//the add update replaces the whole container, so only send it when we have the rest of it
+		if localContainer, ok := cachedContainer(o.Store, p.Id); ok {
+			localContainer.Size = float64(usage.Bytes)
+			if err := cacheContainer(o.Store, localContainer); err != nil {
+				fmt.Println("could not cache container ", p.Id, err)
+			}
+			if err := p.ContainerEmitter.Emit(ctx, emitter.ContainerAddUpdate, localContainer); err != nil {
+				fmt.Println("could not emit container size", err)
+			}
+		}
+	}
+	return usage, nil
+}
+
+// TotalUsage refreshes the usage of each container in ids, a few at a time, and returns them with the combined cost
+// per epoch at storagePrice.
+func (o *ContainerCaller) TotalUsage(ctx context.Context, p ContainerParameter, ids []string, storagePrice uint64, token tokens.Token) ([]ContainerUsage, uint64, error) {
+	var (
+		mu       sync.Mutex
+		workers  sync.WaitGroup
+		firstErr error
+	)
+	usages := make([]ContainerUsage, len(ids))
+	sem := make(chan struct{}, ListConcurrency)
+	for i, id := range ids {
+		workers.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer func() {
+				<-sem
+				workers.Done()
+			}()
+			containerParams := p
+			containerParams.Id = id
+			usage, err := o.Usage(ctx, containerParams, token)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", id, err)
+			}
+			usages[i] = usage
+		}(i, id)
+	}
+	workers.Wait()
+	var total uint64
+	for _, u := range usages {
+		total += u.EstimatedCost(storagePrice)
+	}
+	return usages, total, firstErr
+}
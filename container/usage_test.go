@@ -0,0 +1,211 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/configwizard/sdk/database"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+func TestUsageApplyIsIncremental(t *testing.T) {
+	sizes := map[string]uint64{"a": 100, "b": 250, "c": 5}
+	headed := map[string]int{}
+	head := func(id string) (ObjectUsage, error) {
+		headed[id]++
+		size, ok := sizes[id]
+		if !ok {
+			return ObjectUsage{}, errors.New("not found")
+		}
+		return ObjectUsage{Size: size, Root: true}, nil
+	}
+	var usage ContainerUsage
+	if err := usage.apply([]string{"a", "b"}, head); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Objects != 2 || usage.Bytes != 350 {
+		t.Fatalf("expected 2 objects and 350 bytes, got %d and %d", usage.Objects, usage.Bytes)
+	}
+	//b is deleted and c is added, only c should be headed
+	if err := usage.apply([]string{"a", "c"}, head); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Objects != 2 || usage.Bytes != 105 {
+		t.Fatalf("expected 2 objects and 105 bytes, got %d and %d", usage.Objects, usage.Bytes)
+	}
+	if headed["a"] != 1 || headed["b"] != 1 || headed["c"] != 1 {
+		t.Errorf("objects headed more than once: %v", headed)
+	}
+	if usage.UpdatedAt == 0 {
+		t.Error("refresh time not recorded")
+	}
+	if err := usage.apply([]string{"a", "missing"}, head); err == nil {
+		t.Error("expected the head error to be returned")
+	}
+}
+
+func TestUsageEstimatedCost(t *testing.T) {
+	cases := []struct {
+		name  string
+		usage ContainerUsage
+		price uint64
+		want  uint64
+	}{
+		{"empty", ContainerUsage{}, 1000, 0},
+		{"one GiB", ContainerUsage{Bytes: gibibyte, Replicas: 1}, 1000, 1000},
+		{"replicas multiply", ContainerUsage{Bytes: gibibyte, Replicas: 3}, 1000, 3000},
+		{"no policy counts once", ContainerUsage{Bytes: gibibyte}, 1000, 1000},
+		{"part of a GiB", ContainerUsage{Bytes: gibibyte / 4, Replicas: 2}, 1000, 500},
+		{"no overflow", ContainerUsage{Bytes: 1 << 50, Replicas: 4}, 1 << 40, 1 << 62},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.usage.EstimatedCost(c.price); got != c.want {
+				t.Errorf("expected %d, got %d", c.want, got)
+			}
+		})
+	}
+}
+
+func TestUsageCache(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	caller := ContainerCaller{Store: store}
+	if _, err := caller.CachedUsage("cnr"); err == nil {
+		t.Fatal("expected no cached usage")
+	}
+	usage := ContainerUsage{ContainerID: "cnr"}
+	if err := usage.apply([]string{"a"}, func(string) (ObjectUsage, error) { return ObjectUsage{Size: 42, Root: true}, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheUsage(store, usage); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := caller.CachedUsage("cnr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Bytes != 42 || cached.Objects != 1 {
+		t.Errorf("cached usage lost fields: %+v", cached)
+	}
+	if cached.ObjectSizes != nil {
+		t.Errorf("only the totals should be stored, got %v", cached.ObjectSizes)
+	}
+}
+
+func TestUsageCountsRootObjects(t *testing.T) {
+	cnrID := cid.ID(sha256.Sum256([]byte("usage")))
+	newObject := func(typ object.Type) *object.Object {
+		obj := object.New()
+		obj.SetContainerID(cnrID)
+		obj.SetType(typ)
+		obj.SetPayloadSize(10)
+		return obj
+	}
+	single := newObject(object.TypeRegular)
+
+	//a large object split into a first part carrying the parent header, a last part and a link
+	parent := newObject(object.TypeRegular)
+	parent.SetPayloadSize(20)
+	parentID := oid.ID(sha256.Sum256([]byte("parent")))
+	firstID := oid.ID(sha256.Sum256([]byte("first")))
+	first := newObject(object.TypeRegular)
+	first.SetParent(parent)
+	last := newObject(object.TypeRegular)
+	last.SetFirstID(firstID)
+	last.SetPreviousID(firstID)
+	last.SetParentID(parentID)
+	last.SetParent(parent)
+	link := newObject(object.TypeLink)
+	link.SetFirstID(firstID)
+	link.SetParentID(parentID)
+	link.SetPayloadSize(1)
+
+	//and one split the old way, which names the parts by a split ID and lists them in the link
+	splitID := object.NewSplitID()
+	oldFirst := newObject(object.TypeRegular)
+	oldFirst.SetSplitID(splitID)
+	oldLast := newObject(object.TypeRegular)
+	oldLast.SetSplitID(splitID)
+	oldLast.SetPreviousID(firstID)
+	oldLast.SetParent(parent)
+	oldLink := newObject(object.TypeRegular)
+	oldLink.SetSplitID(splitID)
+	oldLink.SetChildren(firstID, parentID)
+	oldLink.SetPayloadSize(0)
+
+	lock := newObject(object.TypeLock)
+	lock.SetPayloadSize(1)
+	tombstone := newObject(object.TypeTombstone)
+	tombstone.SetPayloadSize(1)
+
+	heads := map[string]*object.Object{"single": single, "first": first, "last": last, "link": link, "oldFirst": oldFirst, "oldLast": oldLast, "oldLink": oldLink, "lock": lock, "tombstone": tombstone}
+	var ids []string
+	for id := range heads {
+		ids = append(ids, id)
+	}
+	var usage ContainerUsage
+	if err := usage.apply(ids, func(id string) (ObjectUsage, error) {
+		return objectUsage(heads[id]), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Objects != 3 {
+		t.Errorf("expected the single object and the large ones to count, got %d objects", usage.Objects)
+	}
+	if usage.Bytes != 53 {
+		t.Errorf("expected every stored payload in the bytes, got %d", usage.Bytes)
+	}
+	for id, size := range usage.ObjectSizes {
+		if size.Root != (id == "single" || id == "first" || id == "oldFirst") {
+			t.Errorf("%s counted as a root: %v", id, size.Root)
+		}
+	}
+}
+
+func TestUsageObjectSizesSurviveARestart(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	usage := ContainerUsage{ContainerID: "cnr"}
+	if err := usage.apply([]string{"a", "b"}, func(id string) (ObjectUsage, error) {
+		return ObjectUsage{Size: 7, Root: id == "a"}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheUsage(store, usage); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheObjectSizes(store, usage); err != nil {
+		t.Fatal(err)
+	}
+
+	//what Usage starts from after a restart
+	restarted := cachedUsage(store, "cnr")
+	restarted.ObjectSizes = cachedObjectSizes(store, "cnr")
+	headed := 0
+	if err := restarted.apply([]string{"a", "b", "c"}, func(id string) (ObjectUsage, error) {
+		headed++
+		return ObjectUsage{Size: 3, Root: true}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if headed != 1 {
+		t.Errorf("only the new object should be headed, %d were", headed)
+	}
+	if restarted.Objects != 2 || restarted.Bytes != 17 {
+		t.Errorf("expected 2 objects and 17 bytes, got %d and %d", restarted.Objects, restarted.Bytes)
+	}
+	if cached, err := (&ContainerCaller{Store: store}).CachedUsage("cnr"); err != nil || cached.Objects != 1 {
+		t.Errorf("the totals should be stored apart from the sizes, got %+v (%v)", cached, err)
+	}
+}
+
+func TestUsageWithoutGateAccount(t *testing.T) {
+	caller := ContainerCaller{}
+	cnrID := cid.ID(sha256.Sum256([]byte("usage")))
+	if _, err := caller.Usage(context.Background(), ContainerParameter{Id: cnrID.String()}, nil); err == nil {
+		t.Fatal("expected an error without a gate account")
+	}
+}
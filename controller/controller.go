@@ -13,6 +13,7 @@ import (
 	"github.com/configwizard/sdk/object"
 	"github.com/configwizard/sdk/payload"
 	gspool "github.com/configwizard/sdk/pool"
+	"github.com/configwizard/sdk/readwriter"
 	"github.com/configwizard/sdk/tokens"
 	"github.com/configwizard/sdk/utils"
 	"github.com/configwizard/sdk/waitgroup"
@@ -23,13 +24,16 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	neoWallet "github.com/nspcc-dev/neo-go/pkg/wallet"
 	wal "github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
 	"log"
 	"math/big"
 	"strconv"
@@ -185,12 +189,26 @@ type TokenManager interface {
 	AddSessionToken(address, cnrID string, b tokens.Token)
 	NewBearerToken(table eacl.Table, lIat, lNbf, lExp uint64, temporaryKey *keys.PublicKey) (tokens.Token, error)
 	NewSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ContainerVerb, gateKey keys.PublicKey) (tokens.Token, error)
-	FindContainerSessionToken(address string, id cid.ID, epoch uint64) (tokens.Token, error)
-	FindBearerToken(address string, id cid.ID, epoch uint64, operation eacl.Operation) (tokens.Token, error)
+	FindContainerSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64) (tokens.Token, error)
+	FindBearerToken(ctx context.Context, address string, id cid.ID, epoch uint64, operation eacl.Operation) (tokens.Token, error)
+	FindScopedBearerToken(ctx context.Context, address string, scope tokens.Scope, epoch uint64) (tokens.Token, error)
+	NewObjectSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ObjectVerb, issuerKey keys.PublicKey) (tokens.Token, error)
+	FindObjectSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64, verb session.ObjectVerb) (tokens.Token, error)
 	GateKey() wal.Account
 	Type() string
 }
 
+// TokenPersister is a TokenManager that can keep its tokens in the database across restarts.
+type TokenPersister interface {
+	Persist(store *tokens.TokenStore, epoch uint64) error
+	Prune(epoch uint64)
+}
+
+// EpochTracker is a TokenManager that looks tokens up at the network's current epoch.
+type EpochTracker interface {
+	SetEpochs(epochs *tokens.EpochCache)
+}
+
 // Controller manages the frontend and backend/SDK interconnectivity
 type Controller struct {
 	selectedNetwork        utils.Network
@@ -212,6 +230,11 @@ type Controller struct {
 	objectActionMapSync    *sync.Mutex
 	objectActionMap        map[payload.UUID]ObjectActionType    // Maps payload UID to corresponding action
 	containerActionMap     map[payload.UUID]ContainerActionType // Maps payload UID to corresponding action
+	NNS                    container.NNSResolver                // resolves container names, see ContainerNNS
+	nnsSync                *sync.Mutex                          // guards NNS and the last failure to dial it
+	nnsErr                 error                                // why NNS could not be dialed
+	nnsFailedAt            time.Time                            // when NNS could not be dialed, see nnsRetryInterval
+	Epochs                 *tokens.EpochCache                   // the network's current epoch, shared with the token manager
 }
 
 func NewCustomController(wg *sync.WaitGroup, ctx context.Context /*cancelFunc context.CancelFunc,*/, progressBarEmitter emitter.Emitter,
@@ -223,13 +246,21 @@ func NewCustomController(wg *sync.WaitGroup, ctx context.Context /*cancelFunc co
 	if err != nil {
 		return Controller{}, err
 	}
-	tokenManager := tokens.NewPrivateKeyTokenManager(ephemeralAccount, true)
+	tokenManager := tokens.NewPrivateKeyTokenManager(ephemeralAccount)
 	gateKey := tokenManager.GateKey()
 	pl, err := gspool.GetPool(ctx, gateKey.PrivateKey().PrivateKey, utils.RetrieveStoragePeers(network))
 	if err != nil {
 		fmt.Println("error getting pool ", err)
 		log.Fatal(err)
 	}
+	epochs := tokens.NewEpochCache(func(ctx context.Context) (uint64, error) {
+		ni, err := pl.NetworkInfo(ctx, client.PrmNetworkInfo{})
+		if err != nil {
+			return 0, err
+		}
+		return ni.CurrentEpoch(), nil
+	}, tokens.DefaultEpochTTL)
+	tokenManager.SetEpochs(epochs)
 	c := Controller{
 		selectedNetwork:        network,
 		Pl:                     pl,
@@ -245,8 +276,10 @@ func NewCustomController(wg *sync.WaitGroup, ctx context.Context /*cancelFunc co
 		pendingEvents:          make(map[payload.UUID]payload.Payload),
 		objectActionMapSync:    &sync.Mutex{}, //locks recording actions
 		objectEventMapSync:     &sync.Mutex{},
+		nnsSync:                &sync.Mutex{},
 		objectActionMap:        make(map[payload.UUID]ObjectActionType),
 		containerActionMap:     make(map[payload.UUID]ContainerActionType),
+		Epochs:                 epochs,
 	}
 	c.Notifier.ListenAndEmit() //this sends out notifications to the frontend.
 	return c, nil
@@ -284,6 +317,8 @@ func NewMockController(wg *sync.WaitGroup, ctx context.Context /*cancelFunc cont
 		pendingEvents:          make(map[payload.UUID]payload.Payload),
 		objectActionMap:        make(map[payload.UUID]ObjectActionType),
 		containerActionMap:     make(map[payload.UUID]ContainerActionType),
+		objectEventMapSync:     &sync.Mutex{},
+		nnsSync:                &sync.Mutex{},
 	}
 	c.Notifier.ListenAndEmit() //this sends out notifications to the emitter
 	return c, nil
@@ -406,6 +441,8 @@ func NewDefaultController(a Account) (Controller, error) {
 		ProgressHandlerManager: nil,
 		objectActionMap:        make(map[payload.UUID]ObjectActionType),
 		pendingEvents:          make(map[payload.UUID]payload.Payload),
+		objectEventMapSync:     &sync.Mutex{},
+		nnsSync:                &sync.Mutex{},
 	}, nil
 }
 
@@ -430,12 +467,16 @@ func (c *Controller) SignRequest(p payload.Payload) error {
 		return errors.New(utils.ErrorNoSession)
 	}
 	c.logger.Println("c.wallet SignRequest", c.wallet, " - ", utils.GetCallerFunctionName())
+	c.objectEventMapSync.Lock()
 	if _, ok := c.pendingEvents[payload.UUID(p.Uid)]; ok {
+		c.objectEventMapSync.Unlock()
 		//exists. end
 		return errors.New(utils.ErrorPendingInUse)
 	}
 	//if we have a signed request
 	c.pendingEvents[payload.UUID(p.Uid)] = p
+	c.objectEventMapSync.Unlock()
+	//the wallet may answer before Sign returns, so the lock is not held while it signs
 	return c.wallet.Sign(p)
 }
 
@@ -445,6 +486,7 @@ func (c *Controller) UpdateFromPrivateKey(signedPayload payload.Payload) error {
 	if c.wallet == nil {
 		return errors.New(utils.ErrorNoSession)
 	}
+	c.objectEventMapSync.Lock()
 	if p, ok := c.pendingEvents[payload.UUID(signedPayload.Uid)]; ok {
 		updatedPayload := p // Dereference to get a copy of the payload
 		updatedPayload.Complete = true
@@ -453,11 +495,13 @@ func (c *Controller) UpdateFromPrivateKey(signedPayload payload.Payload) error {
 		updatedPayload.Signature.HexPublicKey = signedPayload.Signature.HexPublicKey
 		// Update the map with the new struct
 		c.pendingEvents[payload.UUID(signedPayload.Uid)] = updatedPayload
+		c.objectEventMapSync.Unlock()
 		// Notify through the channel
 		c.logger.Println("updatedPayloadSignature ", updatedPayload.Signature.HexSignature)
 		updatedPayload.ResponseCh <- true
 		return nil
 	}
+	c.objectEventMapSync.Unlock()
 	return errors.New(utils.ErrorNotFound)
 }
 
@@ -466,6 +510,7 @@ func (c *Controller) UpdateFromWalletConnect(signedPayload payload.Payload) erro
 	if c.wallet == nil {
 		return errors.New(utils.ErrorNoSession)
 	}
+	c.objectEventMapSync.Lock()
 	if p, ok := c.pendingEvents[payload.UUID(signedPayload.Uid)]; ok {
 		c.logger.Println("uid ", signedPayload.Uid)
 		updatedPayload := p // Dereference to get a copy of the payload
@@ -480,10 +525,12 @@ func (c *Controller) UpdateFromWalletConnect(signedPayload payload.Payload) erro
 		}
 		// Update the map with the new struct
 		c.pendingEvents[payload.UUID(signedPayload.Uid)] = updatedPayload
+		c.objectEventMapSync.Unlock()
 		// Notify through the channel
 		updatedPayload.ResponseCh <- true
 		return nil
 	}
+	c.objectEventMapSync.Unlock()
 	//it could be a wallet update message
 	c.logger.Println("c.wallet UpdateFromWalletConnect", signedPayload)
 	return errors.New(utils.ErrorNotFound)
@@ -508,6 +555,16 @@ func containerActionCaller(wg *waitgroup.WG, ctx context.Context, p container.Co
 
 	return err
 }
+
+// nnsFailure tells the user a container action could not go ahead without NNS and returns err for the caller.
+func (c *Controller) nnsFailure(title string, err error) error {
+	c.Notifier.QueueNotification(c.Notifier.Notification(
+		title,
+		err.Error(),
+		notification.Error,
+		notification.ActionToast))
+	return err
+}
 func (c *Controller) PerformContainerAction(wg *waitgroup.WG, ctx context.Context, cancelCtx context.CancelFunc, p payload.Parameters, action ContainerActionType) error {
 	fmt.Printf("performing container action  %T -- %s\r\n", action, utils.GetCallerFunctionName())
 	defer cancelCtx()
@@ -549,7 +606,32 @@ func (c *Controller) PerformContainerAction(wg *waitgroup.WG, ctx context.Contex
 		return errors.New("parameters not valid")
 	}
 	var cnrId cid.ID
-	if err := cnrId.DecodeString(p.ID()); err != nil || containerParameters.Verb == 0 { //unknown verb for container unnamed
+	if containerParameters.Id != "" && cnrId.DecodeString(containerParameters.Id) != nil {
+		//not an ID, so a name such as photos.container
+		nns, err := c.ContainerNNS()
+		if err != nil {
+			return c.nnsFailure("could not resolve container name", err)
+		}
+		id, err := container.ResolveContainerID(nns, containerParameters.Id)
+		if err != nil {
+			return c.nnsFailure("could not resolve container name", fmt.Errorf("%s: %w", containerParameters.Id, err))
+		}
+		containerParameters.Id = id.String()
+	}
+	if containerParameters.Domain != "" {
+		//the caller checks the name is free, which it cannot do without NNS
+		nns, err := c.ContainerNNS()
+		if err != nil {
+			return c.nnsFailure("could not check the container name", err)
+		}
+		containerParameters.NNS = nns
+	}
+	if containerParameters.Domain != "" && containerParameters.NamedContainerFee == 0 {
+		if fees, err := c.RetrieveCostInformation(); err == nil {
+			containerParameters.NamedContainerFee = fees.NamedContainerFee
+		}
+	}
+	if err := cnrId.DecodeString(containerParameters.ID()); err != nil || containerParameters.Verb == 0 { //unknown verb for container unnamed
 		fmt.Println("verb is empty. We are going to just attempt the action directly.")
 		//no container ID. lets try anyway
 		if err != nil {
@@ -600,7 +682,7 @@ func (c *Controller) PerformContainerAction(wg *waitgroup.WG, ctx context.Contex
 	if containerParameters.Session { //forcing the creation of new session token for containers every time?
 		fmt.Println("just going to always force session token creation")
 	} else {
-		if tok, err := c.TokenManager.FindBearerToken(c.wallet.Address(), cnrId, p.Epoch(), eacl.OperationSearch); err == nil {
+		if tok, err := c.TokenManager.FindBearerToken(c.ctx, c.wallet.Address(), cnrId, c.CurrentEpoch(), eacl.OperationSearch); err == nil {
 			var t tokens.Token
 			var ok bool
 			if t, ok = tok.(*tokens.BearerToken); !ok { //this needs to change with the manager type
@@ -657,20 +739,7 @@ func (c *Controller) PerformContainerAction(wg *waitgroup.WG, ctx context.Contex
 			fmt.Println("failed to create bearer ", err)
 			return err
 		}
-		switch c.TokenManager.Type() {
-		case tokens.TypePrivateTokenManager:
-			// Attempt to cast 'token' to '*tokens.PrivateContainerSessionToken'
-			if tokManager, ok := c.TokenManager.(*tokens.PrivateKeyTokenManager); ok {
-				privateBearerToken := tokManager.PopulatePrivateBearerToken(bt)
-				token = &privateBearerToken
-			} else {
-				fmt.Println("weird error. Shouldn't be here, no token manager")
-				panic("no token manager - odd error")
-			}
-
-		default:
-			token = &tokens.BearerToken{BearerToken: &bt}
-		}
+		token = c.wrapBearerToken(bt)
 	}
 	neoFSPayload.OutgoingData = token.SignedData()
 
@@ -697,7 +766,10 @@ func (c *Controller) PerformContainerAction(wg *waitgroup.WG, ctx context.Contex
 				//success? add it to list.
 				//fixme - we are signing every time here which will be causing wallet connect to ask for too many signatures
 				var latestPayload payload.Payload
-				if pendingPayload, exists := c.pendingEvents[payload.UUID(neoFSPayload.Uid)]; exists {
+				c.objectEventMapSync.Lock()
+				pendingPayload, exists := c.pendingEvents[payload.UUID(neoFSPayload.Uid)]
+				c.objectEventMapSync.Unlock()
+				if exists {
 					latestPayload = pendingPayload
 				} else {
 					return
@@ -827,17 +899,17 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 		errors.New(utils.ErrorNotObject)
 	}
 
-	for _, e := range quickContainer.ExtendedACL.Records {
-		if e.Operation == objectParameters.ActionOperation {
-			if e.Action == eacl.ActionAllow {
-				//we can access the head of the objects. We can continue without a token
-				//around here we need to check the permissions otherwise this is doomed to fail in public mode
-				if err := objectActionCaller(wg, ctx, objectParameters, actionChan, nil, action); err != nil {
-					fmt.Println("unauthorized access failed attempting ", objectParameters.ActionOperation, err)
-					return err
-				} else {
-					return nil
-				}
+	//if anyone may do this to the container we can continue without a token
+	if eaclTable, err := container.ConvertEACLTableToNeoEAcl(quickContainer.ExtendedACL); err == nil {
+		decision := container.Evaluate(eaclTable, acl.Basic(quickContainer.BasicACL), container.Requester{Role: eacl.RoleOthers}, container.AccessRequest{Operation: objectParameters.ActionOperation})
+		fmt.Println("public access ", objectParameters.ActionOperation, decision.Allowed, decision.Reason)
+		//without the object's headers a filtered record may still deny it, so only an outright allow skips the token
+		if decision.Allowed && !decision.Undecided {
+			if err := objectActionCaller(wg, ctx, objectParameters, actionChan, nil, action); err != nil {
+				fmt.Println("unauthorized access failed attempting ", objectParameters.ActionOperation, err)
+				return err
+			} else {
+				return nil
 			}
 		}
 	}
@@ -847,12 +919,22 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 	/*
 		1. if we have a token, just use it
 	*/
-	if bearerToken, err := c.TokenManager.FindBearerToken(c.wallet.Address(), cnrId, p.Epoch(), p.Operation()); err == nil {
+	scope := tokens.Scope{Container: cnrId, Operations: object.RequiredOperations(p), Object: p.ID()}
+	if bearerToken, err := c.TokenManager.FindScopedBearerToken(c.ctx, c.wallet.Address(), scope, c.CurrentEpoch()); err == nil {
 		if err := objectActionCaller(wg, ctx, objectParameters, actionChan, bearerToken, action); err != nil {
 			return err
 		}
 		return nil // this task has been triggered. No need to continue
 	}
+	verb, hasVerb := tokens.ObjectVerb(p.Operation())
+	if hasVerb {
+		if sessionToken, err := c.TokenManager.FindObjectSessionToken(c.ctx, c.wallet.Address(), cnrId, c.CurrentEpoch(), verb); err == nil {
+			if err := objectActionCaller(wg, ctx, objectParameters, actionChan, sessionToken, action); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
 	///*
 	//	2. try accessing the object directly
 	//*/
@@ -888,22 +970,33 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 		return err
 	}
 	//key := c.TokenManager.GateKey()
-	nodes := utils.RetrieveStoragePeers(c.selectedNetwork)
-	//todo - this all needs sorted
-	bt, err := object.ObjectBearerToken(cnrId, p, keys.PublicKey(pubKey), nodes) // fixme - this won't suffice for containers.
-	//fixme - the expiries are not set
-	//iAt, exp, err := gspool.TokenExpiryValue(ctx, c.Pl, 100)
-	//if err != nil {
-	//	return err
-	//}
-	bearerToken := &tokens.BearerToken{BearerToken: &bt}
+	var token tokens.Token
+	//owners act on their own containers within an object session, there is no eACL to get right
+	objectSession := hasVerb && quickContainer.Owner == c.wallet.Address()
+	if objectSession {
+		iAt, exp, err := gspool.TokenExpiryValue(ctx, c.Pl, 100)
+		if err != nil {
+			return err
+		}
+		if token, err = c.TokenManager.NewObjectSessionToken(iAt, iAt, exp, cnrId, verb, keys.PublicKey(pubKey)); err != nil {
+			return err
+		}
+	} else {
+		nodes := utils.RetrieveStoragePeers(c.selectedNetwork)
+		//todo - this all needs sorted
+		bt, err := object.ObjectBearerToken(cnrId, p, keys.PublicKey(pubKey), nodes) // fixme - this won't suffice for containers.
+		if err != nil {
+			return err
+		}
+		token = &tokens.BearerToken{BearerToken: &bt}
+	}
 	//bearerToken, err := c.TokenManager.NewBearerToken(bt.EACLTable(), iAt, iAt, exp, key.PublicKey()) //mock this out for different wallet types
 	//if err != nil {
 	//	return err
 	//}
 
 	////update the payload to the data to sign
-	neoFSPayload.OutgoingData = bearerToken.SignedData()
+	neoFSPayload.OutgoingData = token.SignedData()
 
 	//c.logger.Println("bearer token data to sign (bearerToken.SignedData()) ", neoFSPayload.OutgoingData)
 	// Wait for the payload to be signed in a separate goroutine
@@ -934,11 +1027,15 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 					return
 				}
 				if act, exists := c.objectActionMap[latestPayload.Uid]; exists {
-					if err := bearerToken.Sign(c.wallet.Address(), latestPayload); err != nil {
+					if err := token.Sign(c.wallet.Address(), latestPayload); err != nil {
 						c.logger.Println("error signing token ", err)
 						return
 					}
-					c.TokenManager.AddBearerToken(c.Account().Address(), cnrId.String(), bearerToken)
+					if objectSession {
+						c.TokenManager.AddSessionToken(c.Account().Address(), cnrId.String(), token)
+					} else {
+						c.TokenManager.AddBearerToken(c.Account().Address(), cnrId.String(), token)
+					}
 					//for certain actions objects need a 'pre-requisite'
 					//we need to run this first. We can use the operation to check
 					//var objectWriteCloser io.WriteCloser
@@ -956,7 +1053,7 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 					//	//thought: you could use the destinationObject to update the UI before its downloaded with an emitter
 					//	//destinationObject.PayloadSize() //use this with the progress bar
 					//}
-					if err := objectActionCaller(wg, ctx, objectParameters, actionChan, bearerToken, act); err != nil {
+					if err := objectActionCaller(wg, ctx, objectParameters, actionChan, token, act); err != nil {
 						//handle the error with the UI (n)
 						c.logger.Println("object error executing action ", err)
 						return
@@ -986,11 +1083,242 @@ func (c *Controller) PerformObjectAction(wg *waitgroup.WG, ctx context.Context,
 	return nil
 }
 
+// SetTokenManager makes m the controller's token manager, e.g. a tokens.WalletConnectTokenManager for a WalletConnect
+// wallet, sharing the controller's epochs with it. Requests are signed for m's gate key from now on.
+func (c *Controller) SetTokenManager(m TokenManager) {
+	if tracker, ok := m.(EpochTracker); ok && c.Epochs != nil {
+		tracker.SetEpochs(c.Epochs)
+	}
+	c.TokenManager = m
+	c.GateKey = m.GateKey()
+}
+
+// RestoreTokens reloads the tokens the token manager saved for the registered wallet and network, dropping any that have
+// expired, and saves new tokens from now on. Call it once c.DB is registered for the wallet. key encrypts the tokens and
+// the gate key at rest, see tokens.DeriveStoreKey. A nil key stores the tokens in the clear but not the gate key, they
+// are only restored for the gate account the token manager was created with.
+func (c *Controller) RestoreTokens(key []byte) error {
+	persister, ok := c.TokenManager.(TokenPersister)
+	if !ok {
+		return errors.New("token manager " + c.TokenManager.Type() + " cannot persist tokens")
+	}
+	if c.DB == nil {
+		return errors.New("no database to restore tokens from")
+	}
+	epoch, err := c.currentEpoch()
+	if err != nil {
+		return err
+	}
+	if err := persister.Persist(tokens.NewTokenStore(c.DB, key), epoch); err != nil {
+		return err
+	}
+	//the stored tokens were issued to the stored gate key
+	c.GateKey = c.TokenManager.GateKey()
+	return nil
+}
+
+// PruneTokens forgets tokens that have expired at the current epoch.
+func (c *Controller) PruneTokens() error {
+	persister, ok := c.TokenManager.(TokenPersister)
+	if !ok {
+		return nil
+	}
+	epoch, err := c.currentEpoch()
+	if err != nil {
+		return err
+	}
+	persister.Prune(epoch)
+	return nil
+}
+
+// ImportToken registers a token exported with tokens.ExportToken, e.g. by a colleague sharing a container, for the
+// loaded wallet. The token must be for the selected network and for this gate key.
+func (c *Controller) ImportToken(data string) (tokens.Export, error) {
+	if c.wallet == nil {
+		return tokens.Export{}, errors.New(utils.ErrorNoSession)
+	}
+	tok, export, err := tokens.ImportToken(data, c.selectedNetwork)
+	if err != nil {
+		return export, err
+	}
+	gateKey := c.TokenManager.GateKey()
+	switch t := tok.(type) {
+	case *tokens.BearerToken:
+		if !t.BearerToken.AssertUser(user.ResolveFromECDSAPublicKey(gateKey.PrivateKey().PrivateKey.PublicKey)) {
+			return export, errors.New("token was issued to someone else")
+		}
+	case *tokens.ContainerSessionToken:
+		if !t.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(gateKey.PublicKey())) {
+			return export, errors.New("token was issued to someone else")
+		}
+	case *tokens.ObjectSessionToken:
+		if !t.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(gateKey.PublicKey())) {
+			return export, errors.New("token was issued to someone else")
+		}
+	}
+	if epoch, err := c.currentEpoch(); err == nil && tok.Lifetime().Expired(epoch) {
+		return export, errors.New("token has expired")
+	}
+	if t, ok := tok.(*tokens.BearerToken); ok {
+		//the token manager only finds bearer tokens of its own kind
+		c.TokenManager.AddBearerToken(c.wallet.Address(), export.Container, c.wrapBearerToken(*t.BearerToken))
+	} else {
+		c.TokenManager.AddSessionToken(c.wallet.Address(), export.Container, tok)
+	}
+	return export, nil
+}
+
+// ShareLink builds a link to base that gives the holder of recipient read access to cnrID for the next epochs, see
+// tokens.ShareLink. The loaded wallet is asked to sign the token for the link.
+func (c *Controller) ShareLink(ctx context.Context, base string, cnrID cid.ID, recipient keys.PublicKey, epochs uint64) (string, error) {
+	if c.wallet == nil {
+		return "", errors.New(utils.ErrorNoSession)
+	}
+	bPubKey, err := hex.DecodeString(c.wallet.PublicKeyHexString())
+	if err != nil {
+		return "", err
+	}
+	var pubKey neofsecdsa.PublicKeyRFC6979
+	if err := pubKey.Decode(bPubKey); err != nil {
+		return "", err
+	}
+	epoch, err := c.currentEpoch()
+	if err != nil {
+		return "", err
+	}
+	issuer := user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(pubKey))
+	token := c.wrapBearerToken(tokens.ShareBearerToken(cnrID, issuer, recipient, epoch, epoch+epochs))
+	if err := c.signToken(ctx, token); err != nil {
+		return "", err
+	}
+	return tokens.ShareLink(base, token, c.selectedNetwork, cnrID)
+}
+
+// wrapBearerToken is bt as the kind of bearer token the token manager signs and finds.
+func (c *Controller) wrapBearerToken(bt bearer.Token) tokens.Token {
+	if tokManager, ok := c.TokenManager.(*tokens.PrivateKeyTokenManager); ok {
+		privateBearerToken := tokManager.PopulatePrivateBearerToken(bt)
+		return &privateBearerToken
+	}
+	return &tokens.BearerToken{BearerToken: &bt}
+}
+
+// signToken asks the loaded wallet to sign token and waits for the signature.
+func (c *Controller) signToken(ctx context.Context, token tokens.Token) error {
+	var neoFSPayload payload.Payload
+	neoFSPayload.Uid = payload.UUID(uuid.New().String())
+	//buffered so a wallet that signs before SignRequest returns does not block
+	neoFSPayload.ResponseCh = make(chan bool, 1)
+	neoFSPayload.OutgoingData = token.SignedData()
+	if err := c.SignRequest(neoFSPayload); err != nil {
+		return err
+	}
+	defer func() {
+		c.objectEventMapSync.Lock()
+		delete(c.pendingEvents, neoFSPayload.Uid)
+		c.objectEventMapSync.Unlock()
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-neoFSPayload.ResponseCh:
+	}
+	c.objectEventMapSync.Lock()
+	latestPayload := c.pendingEvents[neoFSPayload.Uid]
+	c.objectEventMapSync.Unlock()
+	if latestPayload.Signature == nil {
+		return errors.New("token was not signed")
+	}
+	if err := token.Sign(c.wallet.Address(), latestPayload); err != nil {
+		return err
+	}
+	token.SetSignature(*latestPayload.Signature)
+	return nil
+}
+
+// currentEpoch is the network's current epoch, from c.Epochs if the controller has them.
+func (c *Controller) currentEpoch() (uint64, error) {
+	if c.Epochs != nil {
+		return c.Epochs.Current(c.ctx)
+	}
+	ni, err := c.Pl.NetworkInfo(c.ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return 0, err
+	}
+	return ni.CurrentEpoch(), nil
+}
+
+// CurrentEpoch is the network's current epoch, or 0 if it cannot be retrieved, which no token is valid at.
+func (c *Controller) CurrentEpoch() uint64 {
+	epoch, err := c.currentEpoch()
+	if err != nil {
+		fmt.Println("could not retrieve the current epoch ", err)
+	}
+	return epoch
+}
+
+// UploadObject uploads what p reads, payloadSize bytes in all, with create (an ObjectCaller's Create) and reports the
+// progress under p.Name(). When p resumes an upload, what an earlier attempt stored counts as done as soon as it is
+// skipped, so the bar carries on from there. p.ReadWriter must be a DualStream, Create sets its Writer.
+func (c *Controller) UploadObject(wg *waitgroup.WG, ctx context.Context, cancelCtx context.CancelFunc, p object.ObjectParameter, payloadSize int64, create ObjectActionType) error {
+	ds, ok := p.ReadWriter.(*readwriter.DualStream)
+	if !ok {
+		return errors.New("uploads read from a DualStream")
+	}
+	if c.ProgressHandlerManager != nil {
+		counter := c.ProgressHandlerManager.AddProgressHandler(wg, ctx, io.Discard, p.Name(), c.logger)
+		if seeker, ok := ds.Reader.(io.ReadSeeker); ok {
+			ds.Reader = object.NewProgressReader(seeker, counter)
+		} else {
+			//Create reads past what is stored, which counts it
+			ds.Reader = io.TeeReader(ds.Reader, counter)
+		}
+		c.ProgressHandlerManager.StartProgressHandler(wg, ctx, p.Name(), payloadSize)
+	}
+	return c.PerformObjectAction(wg, ctx, cancelCtx, p, create)
+}
+
+// AbortUpload forgets the progress of the resumable upload uploadKey, so the next upload with it starts from the beginning.
+func (c *Controller) AbortUpload(uploadKey string) error {
+	if c.DB == nil {
+		return errors.New(utils.ErrorNoDatabase)
+	}
+	return object.AbortUpload(c.DB, uploadKey)
+}
+
 // fixme - this might want to return more information
 func (c *Controller) NetworkInformation() utils.NetworkData {
 	return utils.RetrieveNetworkFileSystemAddress(c.selectedNetwork)
 }
 
+// nnsRetryInterval is how long ContainerNNS waits before dialing the sidechain again after it could not.
+const nnsRetryInterval = time.Minute
+
+// ContainerNNS returns c.NNS, connecting to the selected network's sidechain for it the first time. After a failure it
+// returns the same error, without dialing again, for nnsRetryInterval.
+func (c *Controller) ContainerNNS() (container.NNSResolver, error) {
+	c.nnsSync.Lock()
+	defer c.nnsSync.Unlock()
+	if c.NNS != nil {
+		return c.NNS, nil
+	}
+	if c.nnsErr != nil && time.Since(c.nnsFailedAt) < nnsRetryInterval {
+		return nil, c.nnsErr
+	}
+	err := errors.New("no sidechain RPC for network " + string(c.selectedNetwork))
+	for _, endpoint := range c.NetworkInformation().SidechainRPC {
+		var nns *container.NNSClient
+		if nns, err = container.DialNNS(c.ctx, endpoint); err != nil {
+			fmt.Println("could not connect to NNS at ", endpoint, err)
+			continue
+		}
+		c.NNS, c.nnsErr = nns, nil
+		return nns, nil
+	}
+	c.nnsErr, c.nnsFailedAt = err, time.Now()
+	return nil, err
+}
+
 type privTmpEvent struct {
 	TxId *string
 	c    *Controller
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/configwizard/sdk/container"
+	"github.com/configwizard/sdk/notification"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/waitgroup"
+)
+
+// recordingNotifier keeps what is queued instead of sending it.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	queued []notification.NewNotification
+}
+
+func (n *recordingNotifier) Notification(title, description, typz string, action notification.NotificationType) notification.NewNotification {
+	return notification.NewNotification{Title: title, Description: description, Type: typz, Action: action}
+}
+
+func (n *recordingNotifier) QueueNotification(not notification.NewNotification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.queued = append(n.queued, not)
+}
+
+func (n *recordingNotifier) ListenAndEmit() {}
+
+func (n *recordingNotifier) End() {}
+
+func TestContainerNNSWaitsAfterAFailure(t *testing.T) {
+	dialErr := errors.New("sidechain unavailable")
+	c := Controller{nnsSync: &sync.Mutex{}, nnsErr: dialErr, nnsFailedAt: time.Now()}
+	if _, err := c.ContainerNNS(); err != dialErr {
+		t.Fatalf("expected the last failure without dialing again, got %v", err)
+	}
+
+	nns := container.NewMemoryNNS()
+	c.NNS = nns
+	if got, err := c.ContainerNNS(); err != nil || got != nns {
+		t.Errorf("expected the connected NNS, got %v (%v)", got, err)
+	}
+}
+
+func TestContainerActionNeedsNNS(t *testing.T) {
+	dialErr := errors.New("sidechain unavailable")
+	cases := map[string]container.ContainerParameter{
+		"a container name": {Id: "photos.container"},
+		"a domain":         {Domain: "photos"},
+	}
+	for name, p := range cases {
+		t.Run(name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			c := Controller{nnsSync: &sync.Mutex{}, nnsErr: dialErr, nnsFailedAt: time.Now(), Notifier: notifier, logger: log.Default()}
+			called := false
+			action := func(wg *waitgroup.WG, ctx context.Context, p container.ContainerParameter, actionChan chan notification.NewNotification, token tokens.Token) error {
+				called = true
+				return nil
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			wg := waitgroup.NewWaitGroup(log.Default())
+			if err := c.PerformContainerAction(wg, ctx, cancel, p, action); err != dialErr {
+				t.Errorf("expected the NNS failure, got %v", err)
+			}
+			wg.Wait()
+			if called {
+				t.Error("the action should not run without NNS")
+			}
+			if len(notifier.queued) != 1 || notifier.queued[0].Type != notification.Error {
+				t.Errorf("expected an error notification, got %+v", notifier.queued)
+			}
+		})
+	}
+}
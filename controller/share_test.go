@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/payload"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/utils"
+	wal "github.com/nspcc-dev/neo-go/pkg/wallet"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+)
+
+// signingEmitter hands signed payloads back to the controller, as the frontend does for a private key wallet.
+// A synchronous emitter answers before the wallet's Sign returns, as emitter.MockSigner does.
+type signingEmitter struct {
+	c           *Controller
+	synchronous bool
+}
+
+func (e signingEmitter) Emit(ctx context.Context, message emitter.EventMessage, p any) error {
+	if message != emitter.RequestSign {
+		return nil
+	}
+	if e.synchronous {
+		return e.c.UpdateFromPrivateKey(p.(payload.Payload))
+	}
+	go e.c.UpdateFromPrivateKey(p.(payload.Payload))
+	return nil
+}
+
+// testShareController is a controller for a private key wallet and gate account, at epoch 10.
+func testShareController(t *testing.T, gate *wal.Account) *Controller {
+	account, err := wal.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenManager := tokens.NewPrivateKeyTokenManager(gate)
+	c := &Controller{
+		selectedNetwork:    utils.TestNet,
+		ctx:                context.Background(),
+		logger:             log.Default(),
+		pendingEvents:      make(map[payload.UUID]payload.Payload),
+		objectEventMapSync: &sync.Mutex{},
+		Epochs: tokens.NewEpochCache(func(ctx context.Context) (uint64, error) {
+			return 10, nil
+		}, 0),
+	}
+	c.SetTokenManager(&tokenManager)
+	wallet := &RawAccount{WalletAddress: account.Address, PublicKey: account.PublicKey().StringCompressed(), Account: account}
+	wallet.SetEmitter(signingEmitter{c: c})
+	c.LoadSession(wallet)
+	return c
+}
+
+func TestShareLinkImportsForTheRecipient(t *testing.T) {
+	sharerGate, err := wal.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientGate, err := wal.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharer := testShareController(t, sharerGate)
+	recipient := testShareController(t, recipientGate)
+	cnrID := cid.ID(sha256.Sum256([]byte("shared")))
+
+	link, err := sharer.ShareLink(context.Background(), "https://app.greenfinch.app/", cnrID, *recipientGate.PublicKey(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tokens.ParseShareLink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sharer.ImportToken(data); err == nil {
+		t.Error("the token is for the recipient, not the sharer's gate key")
+	}
+	export, err := recipient.ImportToken(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Expiry != 15 {
+		t.Errorf("expected the token to expire at epoch 15, got %d", export.Expiry)
+	}
+	read := tokens.Scope{Container: cnrID, Operations: tokens.RequiredOperations(eacl.OperationGet)}
+	tok, err := recipient.TokenManager.FindScopedBearerToken(context.Background(), recipient.wallet.Address(), read, 10)
+	if err != nil {
+		t.Fatalf("the imported token should be found for reading: %s", err)
+	}
+	if _, ok := tok.(*tokens.PrivateBearerToken); !ok {
+		t.Errorf("expected the token as the manager keeps them, got a %T", tok)
+	}
+	write := tokens.Scope{Container: cnrID, Operations: tokens.RequiredOperations(eacl.OperationPut)}
+	if _, err := recipient.TokenManager.FindScopedBearerToken(context.Background(), recipient.wallet.Address(), write, 10); err == nil {
+		t.Error("a shared token should only allow reading")
+	}
+}
+
+func TestShareLinkWithASynchronousSigner(t *testing.T) {
+	gate, err := wal.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientGate, err := wal.NewAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testShareController(t, gate)
+	c.wallet.(*RawAccount).SetEmitter(signingEmitter{c: c, synchronous: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.ShareLink(ctx, "https://app.greenfinch.app/", cid.ID(sha256.Sum256([]byte("shared"))), *recipientGate.PublicKey(), 5); err != nil {
+		t.Fatalf("signing should not wait on a wallet that has already answered: %s", err)
+	}
+	if len(c.pendingEvents) != 0 {
+		t.Errorf("expected the signed payload to be removed, %d left", len(c.pendingEvents))
+	}
+}
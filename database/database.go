@@ -53,6 +53,9 @@ const (
 	ObjectBucket          = "objects"
 	AddressBookBucket     = "address_book"
 	NotificationBucket    = "notification"
+	UploadBucket          = "uploads"
+	UsageBucket           = "usage"
+	TokenBucket           = "tokens"
 )
 
 func New(dbPath string) *Bolt {
@@ -122,5 +125,17 @@ func createChildBucketsForNetwork(wallet string, network *bolt.Bucket) error {
 	if err != nil {
 		return fmt.Errorf("creating bucket failed: %s", err)
 	}
+	_, err = userBucket.CreateBucketIfNotExists([]byte(UploadBucket))
+	if err != nil {
+		return fmt.Errorf("creating bucket failed: %s", err)
+	}
+	_, err = userBucket.CreateBucketIfNotExists([]byte(UsageBucket))
+	if err != nil {
+		return fmt.Errorf("creating bucket failed: %s", err)
+	}
+	_, err = userBucket.CreateBucketIfNotExists([]byte(TokenBucket))
+	if err != nil {
+		return fmt.Errorf("creating bucket failed: %s", err)
+	}
 	return err
 }
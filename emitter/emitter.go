@@ -23,9 +23,12 @@ const (
 	ContainerAddUpdate        EventMessage = "container_add_update"
 	HeadRetrieved             EventMessage = "head_retrieved" //used when not part of a larger asynchronous request
 	ContainerRemoveUpdate     EventMessage = "container_remove_update"
+	ContainerUsageUpdate      EventMessage = "container_usage_update"
+	ContainerCloneUpdate      EventMessage = "container_clone_update"
 	ObjectAddUpdate           EventMessage = "object_add_update"
 	ObjectRangeUpdate         EventMessage = "object_range_update"
 	ObjectRemoveUpdate        EventMessage = "object_remove_update"
+	ObjectTreeUpdate          EventMessage = "object_tree_update"
 	ObjectFailed              EventMessage = "object_failed"
 	ContactAddUpdate          EventMessage = "contact_add_update"
 	ContactRemoveUpdate       EventMessage = "contact_remote_update"
@@ -50,9 +53,12 @@ var AllEventMessages = []struct {
 	{ContainerRestrictUpdate, "ContainerRestrictUpdate"},
 	{HeadRetrieved, "HeadRetrieved"},
 	{ContainerRemoveUpdate, "ContainerRemoveUpdate"},
+	{ContainerUsageUpdate, "ContainerUsageUpdate"},
+	{ContainerCloneUpdate, "ContainerCloneUpdate"},
 	{ObjectAddUpdate, "ObjectAddUpdate"},
 	{ObjectRangeUpdate, "ObjectRangeUpdate"},
 	{ObjectRemoveUpdate, "ObjectRemoveUpdate"},
+	{ObjectTreeUpdate, "ObjectTreeUpdate"},
 	{ObjectFailed, "ObjectFailed"},
 	{ContactAddUpdate, "ContactAddUpdate"},
 	{ContactRemoveUpdate, "ContactRemoveUpdate"},
@@ -0,0 +1,211 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/waitgroup"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"sync"
+)
+
+// CopyObject copies the object src.Id from the container of src into the container of dst, keeping its attributes.
+// src.Id can be the root of a large split object, the network reassembles it as it is read and it is split again,
+// by the destination network's object size limit, as it is written. The payload is streamed and never held in memory.
+// srcToken and dstToken are bearer tokens for reading and writing, either can be nil if the gate account needs none.
+func CopyObject(ctx context.Context, src, dst ObjectParameter, srcToken, dstToken tokens.Token) (oid.ID, error) {
+	return copyObject(ctx, src, dst, srcToken, dstToken, io.Discard)
+}
+
+// copyObject is CopyObject, also writing the payload to counter as it is copied.
+func copyObject(ctx context.Context, src, dst ObjectParameter, srcToken, dstToken tokens.Token, counter io.Writer) (oid.ID, error) {
+	var id oid.ID
+	//read what is stored, verifying and decrypting are for people reading it, not copies.
+	src.Verify = false
+	src.DecryptionKey = nil
+	hdr, reader, err := InitReader(ctx, src, srcToken)
+	if err != nil {
+		return id, err
+	}
+	defer reader.Close()
+	if hdr.Type() != object.TypeRegular {
+		return id, fmt.Errorf("object %s is a %s, only regular objects can be copied", src.Id, hdr.Type())
+	}
+	return copyPayload(hdr, reader, counter, func(attrs []object.Attribute) (payloadWriter, error) {
+		return initCopyWriter(ctx, dst, attrs, dstToken)
+	})
+}
+
+// payloadWriter writes an object's payload and knows its ID once closed, like slicer.PayloadWriter.
+type payloadWriter interface {
+	io.WriteCloser
+	ID() oid.ID
+}
+
+// copyPayload writes the payload of the object hdr, read from reader, to a writer that initWriter opens with the
+// object's attributes.
+func copyPayload(hdr object.Object, reader io.Reader, counter io.Writer, initWriter func(attrs []object.Attribute) (payloadWriter, error)) (oid.ID, error) {
+	var id oid.ID
+	writer, err := initWriter(hdr.Attributes())
+	if err != nil {
+		return id, err
+	}
+	if _, err := io.Copy(writer, io.TeeReader(reader, counter)); err != nil {
+		writer.Close()
+		return id, err
+	}
+	if err := writer.Close(); err != nil {
+		return id, err
+	}
+	return writer.ID(), nil
+}
+
+// initCopyWriter is InitWriter for a copy: the attributes are kept as they are rather than given a new timestamp and expiry.
+func initCopyWriter(ctx context.Context, dst ObjectParameter, attrs []object.Attribute, token tokens.Token) (*slicer.PayloadWriter, error) {
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(dst.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return nil, err
+	}
+	gA, err := dst.ForUser()
+	if err != nil {
+		return nil, err
+	}
+	sdkCli, err := dst.Pool().RawClient()
+	if err != nil {
+		return nil, err
+	}
+	ni, err := sdkCli.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return nil, fmt.Errorf("network info: %w", err)
+	}
+	var opts slicer.Options
+	opts.SetObjectPayloadLimit(ni.MaxObjectSize())
+	opts.SetCurrentNeoFSEpoch(ni.CurrentEpoch())
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return nil, errors.New("no bearer token provided")
+			} else {
+				opts.SetBearerToken(*tok.BearerToken)
+			}
+		} else {
+			opts.SetBearerToken(*tok.BearerToken)
+		}
+	}
+	if !ni.HomomorphicHashingDisabled() {
+		opts.CalculateHomomorphicChecksum()
+	}
+	userID := user.ResolveFromECDSAPublicKey(dst.PublicKey)
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetType(object.TypeRegular)
+	hdr.SetOwnerID(&userID)
+	hdr.SetCreationEpoch(ni.CurrentEpoch())
+	hdr.SetAttributes(attrs...)
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	return slicer.InitPut(ctx, sdkCli, hdr, gateSigner, opts)
+}
+
+// CopyReport is what CopyContainer copied, by source object ID.
+type CopyReport struct {
+	Total  int               `json:"total"`
+	Copied map[string]string `json:"copied"` //source ID to the ID of the copy
+	Failed map[string]string `json:"failed"` //source ID to why it was not copied
+}
+
+// CopyContainer copies every selected root object in the container of src into the container of dst with CopyObject.
+// Only regular objects are copied, tombstones and locks belong to the source. Key objects, which share an encrypted
+// object, are not copied as they are, they name the source object: the copy of the encrypted object is shared with the
+// same recipients instead. An object that fails to copy does not stop the others, it is recorded in the report.
+// progress, if set, is called with the report so far after each object, one call at a time, and must not keep the
+// report's maps.
+func CopyContainer(wg *waitgroup.WG, ctx context.Context, src, dst ObjectParameter, opts TransferOptions, srcToken, dstToken tokens.Token, progress func(CopyReport)) (CopyReport, error) {
+	report := CopyReport{Copied: make(map[string]string), Failed: make(map[string]string)}
+	var objects, keyObjects []Object
+	var total int64
+	query := SearchQuery{Filters: []SearchFilter{{Key: object.FilterType, Value: object.TypeRegular.EncodeToString(), Match: object.MatchStringEqual}}}
+	if _, err := search(ctx, src, query, srcToken, func(localObject Object) error {
+		if localObject.Attributes[AttributeEncryptedObject] != "" {
+			keyObjects = append(keyObjects, localObject)
+		} else if opts.selected(objectPath(localObject)) {
+			objects = append(objects, localObject)
+			total += int64(localObject.Size)
+		}
+		return nil
+	}); err != nil {
+		return report, err
+	}
+	counter := opts.startProgress(wg, ctx, total)
+	return copyObjects(ctx, objects, keyObjects, opts.concurrency(), func(obj Object) (oid.ID, error) {
+		objectParams := src
+		objectParams.Id = obj.Id
+		return copyObject(ctx, objectParams, dst, srcToken, dstToken, counter)
+	}, func(attrs []object.Attribute) (string, error) {
+		keyObject, err := putKeyObject(ctx, dst, attrs, dstToken)
+		return keyObject.Id, err
+	}, progress)
+}
+
+// copyObjects copies each of objects with copyOne, concurrency at a time, recording what was copied and what failed.
+// Then the copies of the encrypted objects among them are shared again: each of keyObjects that shares one of objects
+// is put for the copy with putKey. A key object whose encrypted object was not copied is recorded as failed, the others
+// are left out.
+func copyObjects(ctx context.Context, objects, keyObjects []Object, concurrency int, copyOne func(Object) (oid.ID, error), putKey func([]object.Attribute) (string, error), progress func(CopyReport)) (CopyReport, error) {
+	copying := make(map[string]bool)
+	for _, obj := range objects {
+		copying[obj.Id] = true
+	}
+	var shares []Object
+	for _, keyObject := range keyObjects {
+		if copying[keyObject.Attributes[AttributeEncryptedObject]] {
+			shares = append(shares, keyObject)
+		}
+	}
+	report := CopyReport{Total: len(objects) + len(shares), Copied: make(map[string]string), Failed: make(map[string]string)}
+	var mu sync.Mutex
+	var jobs []func() error
+	for _, obj := range objects {
+		obj := obj
+		jobs = append(jobs, func() error {
+			id, err := copyOne(obj)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failed[obj.Id] = err.Error()
+			} else {
+				report.Copied[obj.Id] = id.String()
+			}
+			if progress != nil {
+				progress(report)
+			}
+			return nil
+		})
+	}
+	if err := transfer(ctx, concurrency, jobs); err != nil {
+		return report, err
+	}
+	for _, keyObject := range shares {
+		encryptedID := keyObject.Attributes[AttributeEncryptedObject]
+		if newID, ok := report.Copied[encryptedID]; !ok {
+			report.Failed[keyObject.Id] = "encrypted object " + encryptedID + " was not copied"
+		} else if attrs, err := movedKeyObject(keyObject, newID); err != nil {
+			report.Failed[keyObject.Id] = err.Error()
+		} else if id, err := putKey(attrs); err != nil {
+			report.Failed[keyObject.Id] = err.Error()
+		} else {
+			report.Copied[keyObject.Id] = id
+		}
+		if progress != nil {
+			progress(report)
+		}
+	}
+	return report, nil
+}
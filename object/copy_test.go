@@ -0,0 +1,175 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// fakeCopyWriter keeps the payload written to it and returns id once closed.
+type fakeCopyWriter struct {
+	bytes.Buffer
+	id     oid.ID
+	closed bool
+}
+
+func (w *fakeCopyWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func (w *fakeCopyWriter) ID() oid.ID {
+	return w.id
+}
+
+// failingCopyWriter fails every write.
+type failingCopyWriter struct {
+	closed bool
+}
+
+func (w *failingCopyWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func (w *failingCopyWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func (w *failingCopyWriter) ID() oid.ID {
+	return oid.ID{}
+}
+
+func TestCopyPayload(t *testing.T) {
+	var hdr object.Object
+	hdr.SetType(object.TypeRegular)
+	name := object.NewAttribute(object.AttributeFileName, "a.txt")
+	hdr.SetAttributes(*name)
+	writer := &fakeCopyWriter{id: oid.ID(sha256.Sum256([]byte("copy")))}
+	var counted bytes.Buffer
+	id, err := copyPayload(hdr, strings.NewReader("payload"), &counted, func(attrs []object.Attribute) (payloadWriter, error) {
+		if len(attrs) != 1 || attrs[0].Key() != object.AttributeFileName || attrs[0].Value() != "a.txt" {
+			t.Errorf("the copy should keep the attributes, got %v", attrs)
+		}
+		return writer, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != writer.id {
+		t.Errorf("expected the ID of the copy %s, got %s", writer.id, id)
+	}
+	if writer.String() != "payload" || counted.String() != "payload" {
+		t.Errorf("copied %q and counted %q, want the payload", writer.String(), counted.String())
+	}
+	if !writer.closed {
+		t.Error("the writer should be closed")
+	}
+
+	_, err = copyPayload(hdr, strings.NewReader("payload"), &counted, func([]object.Attribute) (payloadWriter, error) {
+		return nil, errors.New("no space")
+	})
+	if err == nil {
+		t.Error("a writer that cannot be opened should fail the copy")
+	}
+
+	failing := &failingCopyWriter{}
+	if _, err = copyPayload(hdr, strings.NewReader("payload"), &counted, func([]object.Attribute) (payloadWriter, error) {
+		return failing, nil
+	}); err == nil {
+		t.Error("a write that fails should fail the copy")
+	}
+	if !failing.closed {
+		t.Error("the writer should be closed after a failed write")
+	}
+}
+
+func TestCopyObjects(t *testing.T) {
+	objects := []Object{{Id: "a"}, {Id: "b"}, {Id: "c"}}
+	copied := oid.ID(sha256.Sum256([]byte("copied")))
+	var calls int
+	report, err := copyObjects(context.Background(), objects, nil, 2, func(obj Object) (oid.ID, error) {
+		if obj.Id == "b" {
+			return oid.ID{}, errors.New("read failed")
+		}
+		return copied, nil
+	}, func([]object.Attribute) (string, error) {
+		t.Error("there are no key objects to put")
+		return "", nil
+	}, func(CopyReport) {
+		calls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 3 || len(report.Copied) != 2 || len(report.Failed) != 1 {
+		t.Fatalf("expected 3 objects with 2 copied and 1 failed, got %+v", report)
+	}
+	if report.Copied["a"] != copied.String() || report.Failed["b"] != "read failed" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if calls != 3 {
+		t.Errorf("expected progress after each object, got %d calls", calls)
+	}
+}
+
+func TestCopyObjectsSharesEncryptedCopies(t *testing.T) {
+	keyObject := func(id, encryptedID, recipient string) Object {
+		return Object{Id: id, Attributes: map[string]string{
+			AttributeEncryptedObject:                 encryptedID,
+			AttributeEncryptionKeyFor:                recipient,
+			AttributeEncryptionKeyPrefix + recipient: "wrapped-" + recipient,
+		}}
+	}
+	objects := []Object{{Id: "a"}, {Id: "b"}}
+	keyObjects := []Object{
+		keyObject("key-a", "a", "01"),
+		keyObject("key-b", "b", "02"),
+		keyObject("key-c", "c", "03"), //c was not selected
+	}
+	copied := oid.ID(sha256.Sum256([]byte("copied")))
+	var put [][]object.Attribute
+	var calls int
+	report, err := copyObjects(context.Background(), objects, keyObjects, 2, func(obj Object) (oid.ID, error) {
+		if obj.Id == "b" {
+			return oid.ID{}, errors.New("read failed")
+		}
+		return copied, nil
+	}, func(attrs []object.Attribute) (string, error) {
+		put = append(put, attrs)
+		return "new-key", nil
+	}, func(CopyReport) {
+		calls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 4 || calls != 4 {
+		t.Errorf("expected 2 objects and the key objects sharing them, got a total of %d and %d progress calls", report.Total, calls)
+	}
+	if report.Copied["key-a"] != "new-key" {
+		t.Errorf("the copy of a should be shared again, got %+v", report)
+	}
+	if _, ok := report.Failed["key-b"]; !ok {
+		t.Errorf("the key object of b, which was not copied, should fail, got %+v", report)
+	}
+	if _, ok := report.Copied["key-c"]; ok {
+		t.Error("the key object of an object that was not selected should be left out")
+	}
+	if len(put) != 1 {
+		t.Fatalf("expected one key object put, got %d", len(put))
+	}
+	attrs := make(map[string]string)
+	for _, a := range put[0] {
+		attrs[a.Key()] = a.Value()
+	}
+	if attrs[AttributeEncryptedObject] != copied.String() || attrs[AttributeEncryptionKeyFor] != "01" || attrs[AttributeEncryptionKeyPrefix+"01"] != "wrapped-01" {
+		t.Errorf("the key object should share the copy with the same recipient and key, got %v", attrs)
+	}
+}
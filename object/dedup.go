@@ -0,0 +1,74 @@
+package object
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/readwriter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"io"
+	"strconv"
+)
+
+// PayloadChecksum returns the SHA-256 of everything r produces and its length, which is what NeoFS records as the
+// payload checksum and payload size of an object.
+func PayloadChecksum(r io.Reader) ([sha256.Size]byte, uint64, error) {
+	var sum [sha256.Size]byte
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return sum, 0, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, uint64(n), nil
+}
+
+// findDuplicate hashes the payload that is about to be uploaded and searches the container for an object with the same
+// checksum and size. The reader is rewound afterwards so the upload can go ahead if nothing was found. A payload that
+// cannot be rewound, or an upload within an object session, is simply uploaded.
+func findDuplicate(ctx context.Context, p ObjectParameter, token tokens.Token) (Object, bool, error) {
+	if p.Encrypt {
+		//the stored checksum is of the ciphertext, which is different every time
+		return Object{}, false, errors.New("encrypted uploads cannot be deduplicated")
+	}
+	if _, ok := tokens.ObjectSession(token); ok {
+		//a session for the upload does not allow the search and heads
+		fmt.Println("uploading within an object session, uploading without deduplication")
+		return Object{}, false, nil
+	}
+	ds, ok := p.ReadWriter.(*readwriter.DualStream)
+	if !ok {
+		return Object{}, false, errors.New("not a dual stream")
+	}
+	seeker, ok := ds.Reader.(io.ReadSeeker)
+	if !ok {
+		fmt.Println("payload cannot be rewound, uploading without deduplication")
+		return Object{}, false, nil
+	}
+	sum, size, err := PayloadChecksum(seeker)
+	if err != nil {
+		return Object{}, false, err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return Object{}, false, err
+	}
+	query := SearchQuery{
+		Filters: []SearchFilter{
+			{Key: object.FilterPayloadChecksum, Value: hex.EncodeToString(sum[:]), Match: object.MatchStringEqual},
+			{Key: object.FilterPayloadSize, Value: strconv.FormatUint(size, 10), Match: object.MatchStringEqual},
+		},
+		Limit: 1,
+	}
+	var existing Object
+	page, err := search(ctx, p, query, token, func(localObject Object) error {
+		existing = localObject
+		return nil
+	})
+	if err != nil {
+		return Object{}, false, err
+	}
+	return existing, page.Emitted > 0, nil
+}
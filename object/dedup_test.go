@@ -0,0 +1,57 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/configwizard/sdk/readwriter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+)
+
+func TestPayloadChecksum(t *testing.T) {
+	sum, size, err := PayloadChecksum(strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != sha256.Sum256([]byte("payload")) || size != 7 {
+		t.Errorf("checksum %x of %d bytes", sum, size)
+	}
+}
+
+func TestFindDuplicateWithoutSeeking(t *testing.T) {
+	payload := &bytes.Buffer{}
+	payload.WriteString("payload")
+	p := ObjectParameter{Deduplicate: true, ReadWriter: &readwriter.DualStream{Reader: io.MultiReader(payload)}}
+	_, found, err := findDuplicate(context.Background(), p, nil)
+	if err != nil || found {
+		t.Fatalf("a payload that cannot be rewound should be uploaded as it is, found %v, %v", found, err)
+	}
+	if payload.String() != "payload" {
+		t.Error("nothing should have been read from the payload")
+	}
+}
+
+func TestFindDuplicateRejectsEncryption(t *testing.T) {
+	p := ObjectParameter{Deduplicate: true, Encrypt: true, ReadWriter: &readwriter.DualStream{Reader: strings.NewReader("payload")}}
+	if _, _, err := findDuplicate(context.Background(), p, nil); err == nil {
+		t.Fatal("an encrypted upload never matches a stored checksum")
+	}
+}
+
+func TestFindDuplicateWithinSession(t *testing.T) {
+	payload := strings.NewReader("payload")
+	p := ObjectParameter{Deduplicate: true, ReadWriter: &readwriter.DualStream{Reader: payload}}
+	token := &tokens.ObjectSessionToken{SessionToken: new(session.Object)}
+	_, found, err := findDuplicate(context.Background(), p, token)
+	if err != nil || found {
+		t.Fatalf("an upload within a session should be uploaded as it is, found %v, %v", found, err)
+	}
+	if payload.Len() != 7 {
+		t.Error("nothing should have been read from the payload")
+	}
+}
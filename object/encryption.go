@@ -0,0 +1,502 @@
+package object
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/database"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Encrypted objects carry everything needed to decrypt them in their attributes, apart from a private key.
+// The payload is AES-256-GCM in chunks, each chunk sealed with a nonce made of a random prefix, the chunk counter
+// and a flag for the final chunk, so chunks cannot be reordered, dropped or the payload truncated without detection.
+// The data key is wrapped to each reader's public key with ECDH on secp256r1 and stored under
+// AttributeEncryptionKeyPrefix followed by the hex of their compressed public key.
+const (
+	AttributeEncryption          = "Encryption"
+	AttributeEncryptionChunkSize = "EncryptionChunkSize"
+	AttributeEncryptionNonce     = "EncryptionNonce"
+	AttributeEncryptionKeyPrefix = "EncryptionKey-"
+	//a key object shares an encrypted object after it was uploaded. It is an empty object holding one more wrapped key.
+	AttributeEncryptedObject  = "EncryptedObject"
+	AttributeEncryptionKeyFor = "EncryptionKeyFor"
+
+	encryptionScheme       = "AES-256-GCM-STREAM"
+	defaultEncryptionChunk = 64 << 10
+	noncePrefixSize        = 7
+	dataKeySize            = 32
+)
+
+// Contact is an entry of the AddressBookBucket, stored as JSON under any identifier.
+type Contact struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"publicKey"` //hex of the compressed public key
+}
+
+// publicKeyHex is how a public key is written in attribute keys.
+func publicKeyHex(pub *ecdsa.PublicKey) string {
+	return hex.EncodeToString((*keys.PublicKey)(pub).Bytes())
+}
+
+func wrapKDF(shared, ephemeral []byte, recipient string) []byte {
+	h := sha256.New()
+	h.Write(shared)
+	h.Write(ephemeral)
+	h.Write([]byte(recipient))
+	return h.Sum(nil)
+}
+
+// WrapKey encrypts a data key so that only the holder of the private key for recipient can recover it.
+// The result is base64 of the ephemeral public key, the nonce and the sealed key.
+func WrapKey(dataKey []byte, recipient *ecdsa.PublicKey) (string, error) {
+	recipientECDH, err := recipient.ECDH()
+	if err != nil {
+		return "", err
+	}
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	shared, err := ephemeral.ECDH(recipientECDH)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(wrapKDF(shared, ephemeral.PublicKey().Bytes(), publicKeyHex(recipient)))
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	wrapped := append(ephemeral.PublicKey().Bytes(), nonce...)
+	wrapped = aead.Seal(wrapped, nonce, dataKey, nil)
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// UnwrapKey recovers a data key wrapped by WrapKey.
+func UnwrapKey(wrapped string, priv *ecdsa.PrivateKey) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	const ephemeralSize = 65 //uncompressed P-256 point
+	if len(raw) < ephemeralSize {
+		return nil, errors.New("wrapped key is too short")
+	}
+	ephemeral, err := ecdh.P256().NewPublicKey(raw[:ephemeralSize])
+	if err != nil {
+		return nil, err
+	}
+	privECDH, err := priv.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	shared, err := privECDH.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(wrapKDF(shared, raw[:ephemeralSize], publicKeyHex(&priv.PublicKey)))
+	if err != nil {
+		return nil, err
+	}
+	raw = raw[ephemeralSize:]
+	if len(raw) < aead.NonceSize() {
+		return nil, errors.New("wrapped key is too short")
+	}
+	dataKey, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("could not unwrap the key, it was not wrapped for this key")
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// newEnvelope creates a data key for a new object and the attributes describing it, with the key wrapped to owner.
+func newEnvelope(owner *ecdsa.PublicKey) ([]object.Attribute, []byte, []byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, nil, err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, nil, nil, err
+	}
+	wrapped, err := WrapKey(dataKey, owner)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	attrs := make([]object.Attribute, 4)
+	attrs[0].SetKey(AttributeEncryption)
+	attrs[0].SetValue(encryptionScheme)
+	attrs[1].SetKey(AttributeEncryptionChunkSize)
+	attrs[1].SetValue(strconv.Itoa(defaultEncryptionChunk))
+	attrs[2].SetKey(AttributeEncryptionNonce)
+	attrs[2].SetValue(base64.StdEncoding.EncodeToString(prefix))
+	attrs[3].SetKey(AttributeEncryptionKeyPrefix + publicKeyHex(owner))
+	attrs[3].SetValue(wrapped)
+	return attrs, dataKey, prefix, nil
+}
+
+// encryptingWriter seals everything written to it in chunks before passing it on to the object writer.
+type encryptingWriter struct {
+	inner     io.WriteCloser
+	aead      cipher.AEAD
+	prefix    []byte
+	counter   uint32
+	chunkSize int
+	buf       []byte
+}
+
+func newEncryptingWriter(inner io.WriteCloser, dataKey, prefix []byte, chunkSize int) (*encryptingWriter, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{inner: inner, aead: aead, prefix: prefix, chunkSize: chunkSize}, nil
+}
+
+func (w *encryptingWriter) seal(chunk []byte, last bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.prefix, w.counter, last), chunk, nil)
+	w.counter++
+	_, err := w.inner.Write(sealed)
+	return err
+}
+
+func (w *encryptingWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	//a full chunk is only sealed once more data arrives, as the final chunk has to be marked as such
+	for len(w.buf) > w.chunkSize {
+		if err := w.seal(w.buf[:w.chunkSize], false); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[w.chunkSize:]
+	}
+	return len(p), nil
+}
+
+func (w *encryptingWriter) Close() error {
+	if err := w.seal(w.buf, true); err != nil {
+		return err
+	}
+	w.buf = nil
+	return w.inner.Close()
+}
+
+func (w *encryptingWriter) ID() oid.ID {
+	if inner, ok := w.inner.(idWriteCloser); ok {
+		return inner.ID()
+	}
+	return oid.ID{}
+}
+
+// decryptingReader opens the chunks written by encryptingWriter.
+type decryptingReader struct {
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	prefix    []byte
+	counter   uint32
+	chunkSize int
+	plain     []byte
+	done      bool
+}
+
+func newDecryptingReader(hdr object.Object, src io.Reader, dataKey []byte) (*decryptingReader, error) {
+	attrs := make(map[string]string)
+	for _, a := range hdr.Attributes() {
+		attrs[a.Key()] = a.Value()
+	}
+	if attrs[AttributeEncryption] != encryptionScheme {
+		return nil, fmt.Errorf("unsupported encryption %q", attrs[AttributeEncryption])
+	}
+	chunkSize, err := strconv.Atoi(attrs[AttributeEncryptionChunkSize])
+	if err != nil || chunkSize <= 0 {
+		return nil, errors.New("invalid encryption chunk size")
+	}
+	prefix, err := base64.StdEncoding.DecodeString(attrs[AttributeEncryptionNonce])
+	if err != nil || len(prefix) != noncePrefixSize {
+		return nil, errors.New("invalid encryption nonce")
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{src: bufio.NewReader(src), aead: aead, prefix: prefix, chunkSize: chunkSize}, nil
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *decryptingReader) next() error {
+	chunk := make([]byte, r.chunkSize+r.aead.Overhead())
+	n, err := io.ReadFull(r.src, chunk)
+	last := false
+	switch err {
+	case nil:
+		if _, peekErr := r.src.Peek(1); peekErr == io.EOF {
+			last = true
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	plain, err := r.aead.Open(chunk[:0], chunkNonce(r.prefix, r.counter, last), chunk[:n], nil)
+	if err != nil {
+		return errors.New("could not decrypt payload, it is corrupt, truncated or the key is wrong")
+	}
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+// IsEncrypted reports whether an object was uploaded through the encryption layer.
+func IsEncrypted(hdr object.Object) bool {
+	for _, a := range hdr.Attributes() {
+		if a.Key() == AttributeEncryption {
+			return true
+		}
+	}
+	return false
+}
+
+// findDataKey unwraps the data key of an encrypted object with params.DecryptionKey. The wrapped key is either on the
+// object itself (the owner) or on a key object that shared it afterwards.
+func findDataKey(ctx context.Context, params ObjectParameter, hdr object.Object, token tokens.Token) ([]byte, error) {
+	if params.DecryptionKey == nil {
+		return nil, errors.New("no decryption key")
+	}
+	attrKey := AttributeEncryptionKeyPrefix + publicKeyHex(&params.DecryptionKey.PublicKey)
+	for _, a := range hdr.Attributes() {
+		if a.Key() == attrKey {
+			return UnwrapKey(a.Value(), params.DecryptionKey)
+		}
+	}
+	id, ok := hdr.ID()
+	if !ok {
+		return nil, errors.New("object has no id")
+	}
+	query := SearchQuery{
+		Filters: []SearchFilter{
+			{Key: AttributeEncryptedObject, Value: id.String(), Match: object.MatchStringEqual},
+			{Key: AttributeEncryptionKeyFor, Value: publicKeyHex(&params.DecryptionKey.PublicKey), Match: object.MatchStringEqual},
+		},
+		Limit: 1,
+	}
+	var wrapped string
+	if _, err := search(ctx, params, query, token, func(keyObject Object) error {
+		wrapped = keyObject.Attributes[attrKey]
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if wrapped == "" {
+		return nil, errors.New("object " + id.String() + " has not been shared with this key")
+	}
+	return UnwrapKey(wrapped, params.DecryptionKey)
+}
+
+// ShareEncryptedObject lets recipient read an encrypted object. Objects are immutable, so rather than re-uploading the
+// payload this uploads an empty key object holding the data key wrapped for recipient. p.DecryptionKey must be able to
+// unwrap the key already, usually because it belongs to the owner.
+func (o *ObjectCaller) ShareEncryptedObject(ctx context.Context, p ObjectParameter, recipient *ecdsa.PublicKey, token tokens.Token) (Object, error) {
+	var objID oid.ID
+	if err := objID.DecodeString(p.ID()); err != nil {
+		fmt.Println("wrong object Id", err)
+		return Object{}, err
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return Object{}, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return Object{}, err
+	}
+	var prmHead client.PrmObjectHead
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return Object{}, errors.New("no bearer token provided")
+			} else {
+				prmHead.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			prmHead.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	hdr, err := p.Pool().ObjectHead(ctx, cnrID, objID, gateSigner, prmHead)
+	if err != nil {
+		return Object{}, err
+	}
+	if !IsEncrypted(*hdr) {
+		return Object{}, errors.New("object " + p.ID() + " is not encrypted")
+	}
+	dataKey, err := findDataKey(ctx, p, *hdr, token)
+	if err != nil {
+		return Object{}, err
+	}
+	wrapped, err := WrapKey(dataKey, recipient)
+	if err != nil {
+		return Object{}, err
+	}
+	recipientHex := publicKeyHex(recipient)
+	return putKeyObject(ctx, p, keyObjectAttributes(p.ID(), recipientHex, wrapped), token)
+}
+
+// keyObjectAttributes are the attributes of a key object holding the data key of encryptedID wrapped for a recipient.
+func keyObjectAttributes(encryptedID, recipientHex, wrapped string) []object.Attribute {
+	attrs := make([]object.Attribute, 3)
+	attrs[0].SetKey(AttributeEncryptedObject)
+	attrs[0].SetValue(encryptedID)
+	attrs[1].SetKey(AttributeEncryptionKeyFor)
+	attrs[1].SetValue(recipientHex)
+	attrs[2].SetKey(AttributeEncryptionKeyPrefix + recipientHex)
+	attrs[2].SetValue(wrapped)
+	return attrs
+}
+
+// putKeyObject uploads an empty key object with attrs into the container of p.
+func putKeyObject(ctx context.Context, p ObjectParameter, attrs []object.Attribute, token tokens.Token) (Object, error) {
+	keyParams := p
+	keyParams.Id = ""
+	keyParams.Encrypt = false
+	keyParams.Attrs = attrs
+	writer, err := InitWriter(ctx, &keyParams, token)
+	if err != nil {
+		return Object{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Object{}, err
+	}
+	keyWriter, ok := writer.(idWriteCloser)
+	if !ok {
+		return Object{}, errors.New("could not retrieve the key object id")
+	}
+	keyObject := Object{
+		ParentID:   p.ParentID(),
+		Id:         keyWriter.ID().String(),
+		Attributes: make(map[string]string),
+	}
+	for _, a := range keyParams.Attrs {
+		keyObject.Attributes[a.Key()] = a.Value()
+	}
+	if p.ObjectEmitter != nil {
+		if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, keyObject); err != nil {
+			fmt.Println("could not emit update", err)
+		}
+	}
+	return keyObject, nil
+}
+
+// movedKeyObject returns the attributes of keyObject, which shares an encrypted object, for sharing its copy newID. The
+// wrapped key does not change, the copy has the same payload and so the same data key.
+func movedKeyObject(keyObject Object, newID string) ([]object.Attribute, error) {
+	recipientHex := keyObject.Attributes[AttributeEncryptionKeyFor]
+	wrapped := keyObject.Attributes[AttributeEncryptionKeyPrefix+recipientHex]
+	if recipientHex == "" || wrapped == "" {
+		return nil, errors.New("key object " + keyObject.Id + " holds no wrapped key")
+	}
+	return keyObjectAttributes(newID, recipientHex, wrapped), nil
+}
+
+// reshareEncryptedObject shares newID, a copy of the encrypted object of p, with everyone the original was shared with.
+// It returns the key objects of the original, which can be deleted along with it.
+func reshareEncryptedObject(ctx context.Context, p ObjectParameter, newID string, token tokens.Token) ([]Object, error) {
+	query := SearchQuery{
+		Filters: []SearchFilter{
+			{Key: AttributeEncryptedObject, Value: p.ID(), Match: object.MatchStringEqual},
+		},
+	}
+	var keyObjects []Object
+	if _, err := search(ctx, p, query, token, func(keyObject Object) error {
+		keyObjects = append(keyObjects, keyObject)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, keyObject := range keyObjects {
+		attrs, err := movedKeyObject(keyObject, newID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := putKeyObject(ctx, p, attrs, token); err != nil {
+			return nil, err
+		}
+	}
+	return keyObjects, nil
+}
+
+// ShareWithContact shares an encrypted object with a contact from the address book.
+func (o *ObjectCaller) ShareWithContact(ctx context.Context, p ObjectParameter, contactID string, token tokens.Token) (Object, error) {
+	if o.Store == nil {
+		return Object{}, errors.New(utils.ErrorNoDatabase)
+	}
+	byt, err := o.Store.Select(database.AddressBookBucket, contactID)
+	if err != nil {
+		return Object{}, err
+	}
+	var contact Contact
+	if err := json.Unmarshal(byt, &contact); err != nil {
+		return Object{}, err
+	}
+	pub, err := keys.NewPublicKeyFromString(strings.TrimSpace(contact.PublicKey))
+	if err != nil {
+		return Object{}, err
+	}
+	return o.ShareEncryptedObject(ctx, p, (*ecdsa.PublicKey)(pub), token)
+}
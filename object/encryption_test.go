@@ -0,0 +1,101 @@
+package object
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestWrapKey(t *testing.T) {
+	owner, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	dataKey := bytes.Repeat([]byte{7}, dataKeySize)
+
+	wrapped, err := WrapKey(dataKey, &owner.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unwrapped, err := UnwrapKey(wrapped, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Fatal("unwrapped key differs")
+	}
+	if _, err := UnwrapKey(wrapped, other); err == nil {
+		t.Fatal("a key wrapped for the owner should not unwrap with another key")
+	}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	owner, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	attrs, dataKey, prefix, err := newEnvelope(&owner.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hdr object.Object
+	hdr.SetAttributes(attrs...)
+	if !IsEncrypted(hdr) {
+		t.Fatal("envelope attributes should mark the object as encrypted")
+	}
+	const chunkSize = 1000
+	for _, size := range []int{0, 1, chunkSize, chunkSize + 1, 5*chunkSize + 17} {
+		payload := make([]byte, size)
+		rand.Read(payload)
+		var stored bytes.Buffer
+		w, err := newEncryptingWriter(nopWriteCloser{&stored}, dataKey, prefix, chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//uneven writes so chunk boundaries do not line up with them
+		for rest := payload; len(rest) > 0; {
+			n := 333
+			if n > len(rest) {
+				n = len(rest)
+			}
+			w.Write(rest[:n])
+			rest = rest[n:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		//a short payload can turn up in any ciphertext by chance
+		if size >= 16 && bytes.Contains(stored.Bytes(), payload) {
+			t.Fatalf("payload of %d bytes was stored in the clear", size)
+		}
+
+		r, err := newDecryptingReader(hdr, bytes.NewReader(stored.Bytes()), dataKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		//the chunk size attribute says 64k, so give the reader the size used here
+		r.chunkSize = chunkSize
+		plain, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%d bytes: %s", size, err)
+		}
+		if !bytes.Equal(plain, payload) {
+			t.Fatalf("%d bytes: decrypted payload differs", size)
+		}
+
+		if size > chunkSize {
+			truncated := stored.Bytes()[:chunkSize+16]
+			r, _ := newDecryptingReader(hdr, bytes.NewReader(truncated), dataKey)
+			r.chunkSize = chunkSize
+			if _, err := io.ReadAll(r); err == nil {
+				t.Fatalf("%d bytes: a truncated payload should not decrypt", size)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"strconv"
+	"time"
+)
+
+// defaultMsPerBlock is used when the network does not report its block interval.
+const defaultMsPerBlock = 1000
+
+// EpochLength is how long an epoch lasts given the network's epoch duration in blocks and milliseconds per block.
+func EpochLength(epochDuration uint64, msPerBlock int64) time.Duration {
+	if msPerBlock <= 0 {
+		msPerBlock = defaultMsPerBlock
+	}
+	return time.Duration(epochDuration) * time.Duration(msPerBlock) * time.Millisecond
+}
+
+// EpochsFor is how many epochs cover d, rounded up so an object never expires before it was asked to.
+func EpochsFor(d time.Duration, epochLength time.Duration) uint64 {
+	if d <= 0 || epochLength <= 0 {
+		return 0
+	}
+	epochs := uint64(d / epochLength)
+	if d%epochLength != 0 {
+		epochs++
+	}
+	return epochs
+}
+
+// expirationEpoch works out the epoch an upload should expire at from its Lifetime or ExpireAt, zero meaning never.
+func expirationEpoch(p *ObjectParameter, ni netmap.NetworkInfo, now time.Time) (uint64, error) {
+	lifetime := p.Lifetime
+	if !p.ExpireAt.IsZero() {
+		if !p.ExpireAt.After(now) {
+			return 0, errors.New("expiry time is in the past")
+		}
+		lifetime = p.ExpireAt.Sub(now)
+	}
+	if lifetime <= 0 {
+		return 0, nil
+	}
+	if ni.EpochDuration() == 0 {
+		return 0, errors.New("network did not report an epoch duration")
+	}
+	return ni.CurrentEpoch() + EpochsFor(lifetime, EpochLength(ni.EpochDuration(), ni.MsPerBlock())), nil
+}
+
+// withExpiration adds the expiration attribute for Lifetime or ExpireAt, unless the caller already set one.
+func withExpiration(p *ObjectParameter, ni netmap.NetworkInfo) ([]object.Attribute, error) {
+	for _, a := range p.Attrs {
+		if a.Key() == object.AttributeExpirationEpoch {
+			return p.Attrs, nil
+		}
+	}
+	epoch, err := expirationEpoch(p, ni, time.Now())
+	if err != nil || epoch == 0 {
+		return p.Attrs, err
+	}
+	var expiryAttr object.Attribute
+	expiryAttr.SetKey(object.AttributeExpirationEpoch)
+	expiryAttr.SetValue(strconv.FormatUint(epoch, 10))
+	return append(p.Attrs, expiryAttr), nil
+}
+
+// ExpiringWithin lists the objects in the container of p that will expire within the next epochs epochs,
+// so they can be warned about or renewed. Each is also emitted through the ObjectEmitter.
+func (o *ObjectCaller) ExpiringWithin(ctx context.Context, p ObjectParameter, epochs uint64, token tokens.Token) ([]Object, error) {
+	ni, err := p.Pool().NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return nil, err
+	}
+	query := SearchQuery{
+		Filters: []SearchFilter{
+			{Key: object.AttributeExpirationEpoch, Value: strconv.FormatUint(ni.CurrentEpoch()+epochs, 10), Match: object.MatchNumLE},
+		},
+	}
+	var expiring []Object
+	_, err = search(ctx, p, query, token, func(localObject Object) error {
+		expiring = append(expiring, localObject)
+		if p.ObjectEmitter == nil {
+			return nil
+		}
+		return p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, localObject)
+	})
+	return expiring, err
+}
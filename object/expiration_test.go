@@ -0,0 +1,50 @@
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+func TestExpirationEpoch(t *testing.T) {
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(100)
+	ni.SetEpochDuration(240)
+	ni.SetMsPerBlock(15000) //an epoch is an hour
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		p    ObjectParameter
+		want uint64
+	}{
+		{"never", ObjectParameter{}, 0},
+		{"exactly two epochs", ObjectParameter{Lifetime: 2 * time.Hour}, 102},
+		{"rounds up", ObjectParameter{Lifetime: 61 * time.Minute}, 102},
+		{"absolute time", ObjectParameter{ExpireAt: now.Add(24 * time.Hour)}, 124},
+		{"absolute wins", ObjectParameter{Lifetime: time.Hour, ExpireAt: now.Add(3 * time.Hour)}, 103},
+	}
+	for _, c := range cases {
+		got, err := expirationEpoch(&c.p, ni, now)
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: got epoch %d, want %d", c.name, got, c.want)
+		}
+	}
+	if _, err := expirationEpoch(&ObjectParameter{ExpireAt: now.Add(-time.Minute)}, ni, now); err == nil {
+		t.Error("an expiry in the past should be refused")
+	}
+
+	var attr object.Attribute
+	attr.SetKey(object.AttributeExpirationEpoch)
+	attr.SetValue("500")
+	p := ObjectParameter{Lifetime: time.Hour, Attrs: []object.Attribute{attr}}
+	attrs, err := withExpiration(&p, ni)
+	if err != nil || len(attrs) != 1 || attrs[0].Value() != "500" {
+		t.Errorf("an explicit expiration attribute should be left alone, got %v", attrs)
+	}
+}
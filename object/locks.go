@@ -0,0 +1,245 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ObjectLock is a LOCK object protecting its members from deletion until the end of epoch Until.
+type ObjectLock struct {
+	Id       string   `json:"id"`
+	ParentID string   `json:"parentID"`
+	Until    uint64   `json:"until"`
+	Members  []string `json:"members"`
+}
+
+func (l ObjectLock) Protects(objectID string) bool {
+	for _, m := range l.Members {
+		if m == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectLockedError is returned by Delete when an object is protected by a lock.
+type ObjectLockedError struct {
+	ObjectID string
+	LockID   string //empty when only the network knows which lock it is
+	Until    uint64
+}
+
+func (e ObjectLockedError) Error() string {
+	if e.LockID == "" {
+		return "object " + e.ObjectID + " is locked"
+	}
+	return fmt.Sprintf("object %s is locked by %s until epoch %d", e.ObjectID, e.LockID, e.Until)
+}
+
+// Lock protects the objects targets, in the container of p, from deletion until the end of untilEpoch by storing a
+// LOCK object referencing them. The lock itself expires after untilEpoch.
+func (o *ObjectCaller) Lock(ctx context.Context, p ObjectParameter, targets []string, untilEpoch uint64, token tokens.Token) (ObjectLock, error) {
+	if len(targets) == 0 {
+		return ObjectLock{}, errors.New("no objects to lock")
+	}
+	members := make([]oid.ID, len(targets))
+	for i, target := range targets {
+		if err := members[i].DecodeString(target); err != nil {
+			fmt.Println("wrong object Id", err)
+			return ObjectLock{}, err
+		}
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return ObjectLock{}, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return ObjectLock{}, err
+	}
+	sdkCli, err := p.Pool().RawClient()
+	if err != nil {
+		return ObjectLock{}, err
+	}
+	ni, err := sdkCli.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return ObjectLock{}, fmt.Errorf("network info: %w", err)
+	}
+	if untilEpoch <= ni.CurrentEpoch() {
+		return ObjectLock{}, fmt.Errorf("lock must last beyond the current epoch %d", ni.CurrentEpoch())
+	}
+	var opts slicer.Options
+	opts.SetObjectPayloadLimit(ni.MaxObjectSize())
+	opts.SetCurrentNeoFSEpoch(ni.CurrentEpoch())
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return ObjectLock{}, errors.New("no bearer token provided")
+			} else {
+				opts.SetBearerToken(*tok.BearerToken)
+			}
+		} else {
+			opts.SetBearerToken(*tok.BearerToken)
+		}
+	}
+	if !ni.HomomorphicHashingDisabled() {
+		opts.CalculateHomomorphicChecksum()
+	}
+	userID := user.ResolveFromECDSAPublicKey(p.PublicKey)
+	var lock object.Lock
+	lock.WriteMembers(members)
+
+	var hdr object.Object
+	hdr.SetContainerID(cnrID)
+	hdr.SetOwnerID(&userID)
+	hdr.SetCreationEpoch(ni.CurrentEpoch())
+	var expiryAttr, timestampAttr object.Attribute
+	expiryAttr.SetKey(object.AttributeExpirationEpoch)
+	expiryAttr.SetValue(strconv.FormatUint(untilEpoch, 10))
+	timestampAttr.SetKey(object.AttributeTimestamp)
+	timestampAttr.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
+	hdr.SetAttributes(expiryAttr, timestampAttr)
+	hdr.WriteLock(lock)
+
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	lockID, err := slicer.Put(ctx, sdkCli, hdr, gateSigner, bytes.NewReader(lock.Marshal()), opts)
+	if err != nil {
+		return ObjectLock{}, err
+	}
+	objectLock := ObjectLock{
+		Id:       lockID.String(),
+		ParentID: p.ParentID(),
+		Until:    untilEpoch,
+		Members:  append([]string{}, targets...),
+	}
+	if p.ObjectEmitter != nil {
+		for _, target := range targets {
+			if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, Object{ParentID: p.ParentID(), Id: target, Attributes: map[string]string{"LockedUntil": strconv.FormatUint(untilEpoch, 10)}}); err != nil {
+				fmt.Println("could not emit update", err)
+			}
+		}
+	}
+	return objectLock, nil
+}
+
+// ListLocks returns the locks in the container of p that are still in force, including those that never expire.
+func (o *ObjectCaller) ListLocks(ctx context.Context, p ObjectParameter, token tokens.Token) ([]ObjectLock, error) {
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return nil, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return nil, err
+	}
+	ni, err := p.Pool().NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return nil, err
+	}
+	query := SearchQuery{
+		Filters: []SearchFilter{
+			{Key: object.FilterType, Value: object.TypeLock.EncodeToString(), Match: object.MatchStringEqual},
+		},
+	}
+	getInit := client.PrmObjectGet{}
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return nil, errors.New("no bearer or object session token provided")
+				} else {
+					getInit.WithinSession(*sessionToken)
+				}
+			} else {
+				getInit.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			getInit.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	var locks []ObjectLock
+	_, err = search(ctx, p, query, token, func(lockObject Object) error {
+		if lockObject.ExpiresAt != 0 && lockObject.ExpiresAt < ni.CurrentEpoch() {
+			return nil
+		}
+		var lockID oid.ID
+		if err := lockID.DecodeString(lockObject.Id); err != nil {
+			return err
+		}
+		//the members are in the payload, which is small
+		hdr, reader, err := p.Pool().ObjectGetInit(ctx, cnrID, lockID, gateSigner, getInit)
+		if err != nil {
+			return err
+		}
+		payload, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		hdr.SetPayload(payload)
+		var lock object.Lock
+		if err := hdr.ReadLock(&lock); err != nil {
+			return err
+		}
+		members := make([]oid.ID, lock.NumberOfMembers())
+		lock.ReadMembers(members)
+		objectLock := ObjectLock{
+			Id:       lockObject.Id,
+			ParentID: lockObject.ParentID,
+			Until:    lockObject.ExpiresAt,
+		}
+		for _, m := range members {
+			objectLock.Members = append(objectLock.Members, m.String())
+		}
+		locks = append(locks, objectLock)
+		return nil
+	})
+	return locks, err
+}
+
+// checkLocks returns an ObjectLockedError naming the lock if any lock in force protects the object of p. Finding the
+// locks needs SEARCH and GET, if they cannot be found the delete goes ahead and the network refuses it if the object is
+// locked, see asLockedError.
+func (o *ObjectCaller) checkLocks(ctx context.Context, p ObjectParameter, token tokens.Token) error {
+	locks, err := o.ListLocks(ctx, p, token)
+	if err != nil {
+		fmt.Println("could not check locks, leaving it to the network ", err)
+		return nil
+	}
+	return lockedBy(p.ID(), locks)
+}
+
+// lockedBy returns an ObjectLockedError for the first of locks that protects objectID.
+func lockedBy(objectID string, locks []ObjectLock) error {
+	for _, l := range locks {
+		if l.Protects(objectID) {
+			return ObjectLockedError{ObjectID: objectID, LockID: l.Id, Until: l.Until}
+		}
+	}
+	return nil
+}
+
+// asLockedError turns the network's locked status into an ObjectLockedError, leaving other errors alone.
+func asLockedError(objectID string, err error) error {
+	if errors.Is(err, apistatus.ErrObjectLocked) {
+		return ObjectLockedError{ObjectID: objectID}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+)
+
+func TestObjectLockProtects(t *testing.T) {
+	lock := ObjectLock{Id: "lock", Until: 10, Members: []string{"a", "b"}}
+	if !lock.Protects("a") || !lock.Protects("b") {
+		t.Error("a lock should protect its members")
+	}
+	if lock.Protects("c") {
+		t.Error("a lock should not protect other objects")
+	}
+	if (ObjectLock{}).Protects("") {
+		t.Error("a lock without members protects nothing")
+	}
+}
+
+func TestLockedBy(t *testing.T) {
+	locks := []ObjectLock{
+		{Id: "other", Until: 20, Members: []string{"b"}},
+		{Id: "lock", Until: 12, Members: []string{"a", "c"}},
+	}
+	err := lockedBy("c", locks)
+	var locked ObjectLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("c is locked, got %v", err)
+	}
+	if locked.ObjectID != "c" || locked.LockID != "lock" || locked.Until != 12 {
+		t.Errorf("expected the lock protecting c, got %+v", locked)
+	}
+	if err := lockedBy("d", locks); err != nil {
+		t.Errorf("d is not locked, got %v", err)
+	}
+}
+
+func TestAsLockedError(t *testing.T) {
+	err := asLockedError("object", fmt.Errorf("delete: %w", apistatus.ObjectLocked{}))
+	var locked ObjectLockedError
+	if !errors.As(err, &locked) || locked.ObjectID != "object" {
+		t.Fatalf("the network's locked status should become an ObjectLockedError, got %v", err)
+	}
+	if locked.Error() != "object object is locked" {
+		t.Errorf("unexpected message %q", locked.Error())
+	}
+	other := errors.New("access denied")
+	if asLockedError("object", other) != other {
+		t.Error("other errors should be left alone")
+	}
+	if asLockedError("object", nil) != nil {
+		t.Error("no error should stay no error")
+	}
+	known := ObjectLockedError{ObjectID: "object", LockID: "lock", Until: 12}
+	if known.Error() != "object object is locked by lock until epoch 12" {
+		t.Errorf("unexpected message %q", known.Error())
+	}
+}
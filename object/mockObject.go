@@ -46,6 +46,20 @@ func (o *MockObject) SynchronousObjectHead(ctx context.Context, cnrId cid.ID, ob
 func (o *MockObject) SearchHeadByAttribute(ctx context.Context, cnrID cid.ID, attr object.Attribute, signer user.Signer, pl *pool.Pool) (Object, error) {
 	return Object{}, nil
 }
+func (o *MockObject) Search(ctx context.Context, p ObjectParameter, query SearchQuery, token tokens.Token) (SearchPage, error) {
+	mockObject := Object{
+		ParentID:   p.ParentID(),
+		Id:         p.ID(),
+		Attributes: make(map[string]string),
+	}
+	for _, f := range query.Filters {
+		mockObject.Attributes[f.Key] = f.Value
+	}
+	if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, mockObject); err != nil {
+		return SearchPage{}, err
+	}
+	return SearchPage{Emitted: 1, NextOffset: query.Offset + 1}, nil
+}
 
 // todo - this will need to handle synchronous requests to the database and then asynchronous requests to the network
 // basically load what we have but update it.
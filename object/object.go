@@ -33,6 +33,7 @@ import (
 type ObjectAction interface {
 	SynchronousObjectHead(ctx context.Context, cnrId cid.ID, objID oid.ID, signer user.Signer, pl *pool.Pool) (Object, error)
 	SearchHeadByAttribute(ctx context.Context, cnrId cid.ID, attribute object.Attribute, signer user.Signer, pl *pool.Pool) (Object, error)
+	Search(ctx context.Context, p ObjectParameter, query SearchQuery, token tokens.Token) (SearchPage, error)
 	Head(wg *waitgroup.WG, ctx context.Context, p payload.Parameters, actionChan chan notification.NewNotification, token tokens.Token) error
 	Create(wg *waitgroup.WG, ctx context.Context, p payload.Parameters, actionChan chan notification.NewNotification, token tokens.Token) error
 	Read(wg *waitgroup.WG, ctx context.Context, p payload.Parameters, actionChan chan notification.NewNotification, token tokens.Token) error
@@ -80,6 +81,20 @@ type ObjectParameter struct {
 	Attrs           []object.Attribute
 	ActionOperation eacl.Operation
 	ExpiryEpoch     uint64
+	//Deduplicate makes Create skip the upload when the container already has an object with the same payload checksum and size.
+	//A payload that cannot seek, or an upload within an object session, is uploaded without checking. It cannot be combined with Encrypt.
+	Deduplicate bool
+	//Lifetime or ExpireAt make an upload expire, converted to an epoch with the network's epoch duration. ExpireAt wins if both are set.
+	Lifetime time.Duration
+	ExpireAt time.Time
+	//Encrypt makes Create encrypt the payload on the client with a key wrapped to PublicKey.
+	Encrypt bool
+	//DecryptionKey, when set, is used to decrypt encrypted objects as they are read.
+	DecryptionKey *ecdsa.PrivateKey
+	//Verify makes Read check the payload against the checksums in the header while it streams.
+	Verify bool
+	//UploadKey makes Create resumable. Progress is persisted under this key and a later Create with the same key carries on from it.
+	UploadKey string
 }
 
 func (o ObjectParameter) Name() string {
@@ -148,8 +163,13 @@ func (o *ObjectCaller) SynchronousObjectHead(ctx context.Context, cnrId cid.ID,
 	if !ok {
 		return Object{}, err
 	}
+	return headerToObject(cnrId, id, hdr)
+}
+
+// headerToObject converts an object header retrieved from the network into our Object.
+func headerToObject(cnrID cid.ID, id oid.ID, hdr *object.Object) (Object, error) {
 	localObject := Object{
-		ParentID:   cnrId.String(),
+		ParentID:   cnrID.String(),
 		Id:         id.String(),
 		Size:       hdr.PayloadSize(),
 		CreatedAt:  time.Time{}.Unix(),
@@ -169,9 +189,14 @@ func (o *ObjectCaller) SynchronousObjectHead(ctx context.Context, cnrId cid.ID,
 		case object.AttributeFileName:
 			localObject.Name = v.Value()
 		case object.AttributeExpirationEpoch:
-			//nothing yet
+			expiresAt, err := strconv.ParseUint(v.Value(), 10, 64)
+			if err != nil {
+				fmt.Println("Error converting string to int:", err)
+				return Object{}, err
+			}
+			localObject.ExpiresAt = expiresAt
 		case object.AttributeFilePath:
-			//nothing yetm
+			localObject.FilePath = NormalisePath(v.Value())
 		}
 		localObject.Attributes[v.Key()] = v.Value()
 	}
@@ -207,7 +232,11 @@ func (o *ObjectCaller) Head(wg *waitgroup.WG, ctx context.Context, p payload.Par
 	if token != nil {
 		if tok, ok := token.(*tokens.BearerToken); !ok {
 			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
-				return errors.New(utils.ErrorNoToken) //in the future we could offer a session token, but not really recommended.
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return errors.New(utils.ErrorNoToken)
+				} else {
+					prmHead.WithinSession(*sessionToken) //an object session instead, e.g. for the container's owner
+				}
 			} else {
 				prmHead.WithBearerToken(*tok.BearerToken) //now we know its a bearer token we can extract it
 			}
@@ -234,35 +263,10 @@ func (o *ObjectCaller) Head(wg *waitgroup.WG, ctx context.Context, p payload.Par
 	if !ok {
 		return errors.New(utils.ErrorNoID)
 	}
-	localObject := Object{
-		ParentID:   cnrID.String(),
-		Id:         id.String(),
-		Size:       hdr.PayloadSize(),
-		CreatedAt:  time.Time{}.Unix(),
-		Attributes: make(map[string]string),
-	}
-	for _, v := range hdr.Attributes() {
-		switch v.Key() {
-		case object.AttributeTimestamp:
-			timestampInt, err := strconv.ParseInt(v.Value(), 10, 64)
-			if err != nil {
-				fmt.Println("Error converting string to int:", err)
-				return err
-			}
-			localObject.CreatedAt = timestampInt
-		case object.AttributeContentType:
-			localObject.ContentType = v.Value()
-		case object.AttributeFileName:
-			localObject.Name = v.Value()
-		case object.AttributeExpirationEpoch:
-			//nothing yet
-		case object.AttributeFilePath:
-			//nothing yet
-		}
-		localObject.Attributes[v.Key()] = v.Value()
+	localObject, err := headerToObject(cnrID, id, hdr)
+	if err != nil {
+		return err
 	}
-	checksum, _ := hdr.PayloadChecksum()
-	localObject.Attributes[payloadChecksumHeader] = checksum.String()
 
 	//sends this wherever it needs to go. If this is needed somewhere else in the app, then a closure can allow this to be accessed elsewhere in a routine.
 	return params.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, localObject)
@@ -290,7 +294,11 @@ func (o *ObjectCaller) Delete(wg *waitgroup.WG, ctx context.Context, p payload.P
 	if token != nil {
 		if tok, ok := token.(*tokens.BearerToken); !ok {
 			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
-				return errors.New("no bearer token provided")
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return errors.New("no bearer or object session token provided")
+				} else {
+					prmDelete.WithinSession(*sessionToken)
+				}
 			} else {
 				prmDelete.WithBearerToken(*tok.BearerToken) //now we know its a bearer token we can extract it
 			}
@@ -300,12 +308,21 @@ func (o *ObjectCaller) Delete(wg *waitgroup.WG, ctx context.Context, p payload.P
 	}
 	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
 	ctx, _ = context.WithTimeout(ctx, 60*time.Second)
+	if err := o.checkLocks(ctx, params, token); err != nil {
+		actionChan <- o.Notification(
+			"delete failed",
+			err.Error(),
+			notification.Error,
+			notification.ActionToast)
+		return err
+	}
 	actionChan <- o.Notification(
 		"deleting object",
 		"deleting object "+objID.String(),
 		notification.Info,
 		notification.ActionToast)
 	if _, err := p.Pool().ObjectDelete(ctx, cnrID, objID, gateSigner, prmDelete); err != nil {
+		err = asLockedError(p.ID(), err)
 		actionChan <- o.Notification(
 			"delete failed",
 			"object "+p.ID()+" failed to delete "+err.Error(),
@@ -431,49 +448,26 @@ func (o *ObjectCaller) SearchHeadByAttribute(ctx context.Context, cnrID cid.ID,
 	addrObj.SetObject(buf[0])
 	return o.SynchronousObjectHead(ctx, cnrID, addrObj.Object(), signer, pl)
 }
-func Ranger(ctx context.Context, params ObjectParameter, token tokens.Token) error {
-	var objID oid.ID
-	if err := objID.DecodeString(params.ID()); err != nil {
-		fmt.Println("wrong object Id", err)
-		return err
-	}
-	var cnrID cid.ID
-	if err := cnrID.DecodeString(params.ParentID()); err != nil {
-		fmt.Println("wrong container Id", err)
-		return err
-	}
-	gA, err := params.ForUser()
+
+// Ranger reads length bytes of an object from offset. The range is emitted as an ObjectRange on the object emitter.
+func Ranger(ctx context.Context, params ObjectParameter, token tokens.Token, offset, length uint64) (ObjectRange, error) {
+	rangeReader, err := NewRangeReader(ctx, params, token, 0)
 	if err != nil {
-		return err
-	}
-	gateSigner := user.NewAutoIDSigner(gA.PrivateKey().PrivateKey) //fix me is this correct signer?
-	rangeInit := client.PrmObjectRange{}
-	if token != nil {
-		if tok, ok := token.(*tokens.BearerToken); !ok {
-			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
-				return errors.New("no bearer token provided")
-			} else {
-				rangeInit.WithBearerToken(*tok.BearerToken) //now we know its a bearer token we can extract it
-			}
-		} else {
-			rangeInit.WithBearerToken(*tok.BearerToken) //now we know its a bearer token we can extract it
-		}
+		return ObjectRange{}, err
 	}
-	offset := uint64(100)
-	length := uint64(100)
-	objRangeReader, err := params.Pool().ObjectRangeInit(ctx, cnrID, objID, offset, length, gateSigner, rangeInit)
-	if err != nil {
-		log.Println("error creating object reader ", err)
-		return err
+	defer rangeReader.Close()
+	buf := make([]byte, length)
+	n, err := rangeReader.ReadAt(buf, int64(offset))
+	if err != nil && err != io.EOF {
+		return ObjectRange{}, err
 	}
+	return ObjectRange{Offset: offset, Limit: uint64(n), Data: buf[:n]}, nil
+}
 
-	var buf []byte
-	_, err = objRangeReader.Read(buf)
-	if err != nil {
-		return err
-	}
-	params.ObjectEmitter.Emit(ctx, emitter.ObjectRangeUpdate, buf)
-	return nil
+// readCloser closes the object reader underneath any verifying or decrypting readers.
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // tmpPreRequisite should be run before trying to retrieve an object. It provides the size of the object and the reader that will do the retrieval.
@@ -497,7 +491,11 @@ func InitReader(ctx context.Context, params ObjectParameter, token tokens.Token)
 	if token != nil {
 		if tok, ok := token.(*tokens.BearerToken); !ok {
 			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
-				return object.Object{}, nil, errors.New("no bearer token provided")
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return object.Object{}, nil, errors.New("no bearer or object session token provided")
+				} else {
+					getInit.WithinSession(*sessionToken)
+				}
 			} else {
 				getInit.WithBearerToken(*tok.BearerToken) //now we know its a bearer token we can extract it
 			}
@@ -510,11 +508,27 @@ func InitReader(ctx context.Context, params ObjectParameter, token tokens.Token)
 		log.Println("error creating object reader ", err)
 		return object.Object{}, nil, err
 	}
+	//verification is of the stored payload, so it happens before decryption
+	var reader io.Reader = objReader
+	if params.Verify {
+		reader = newVerifyingReader(dstObject, reader)
+	}
+	if params.DecryptionKey != nil && IsEncrypted(dstObject) {
+		dataKey, err := findDataKey(ctx, params, dstObject, token)
+		if err != nil {
+			objReader.Close()
+			return object.Object{}, nil, err
+		}
+		if reader, err = newDecryptingReader(dstObject, reader, dataKey); err != nil {
+			objReader.Close()
+			return object.Object{}, nil, err
+		}
+	}
 	//the object reader will need closing.
 	//might need a before(), during(), after() type interface to do this potentially, but not nice. Potentially attach the
 	//dstObject to the parameters so that can be closed in the during() phase.
 	//todo: readers and writers should be attached to the object that owns the method
-	return dstObject, objReader, nil
+	return dstObject, readCloser{Reader: reader, Closer: objReader}, nil
 }
 
 func (o ObjectCaller) Read(wg *waitgroup.WG, ctx context.Context, p payload.Parameters, actionChan chan notification.NewNotification, token tokens.Token) error {
@@ -555,6 +569,15 @@ func (o ObjectCaller) Read(wg *waitgroup.WG, ctx context.Context, p payload.Para
 					notification.ActionToast)
 				break
 			}
+			var mismatch ChecksumMismatchError
+			if errors.As(err, &mismatch) {
+				actionChan <- o.Notification(
+					"integrity check failed",
+					err.Error(),
+					notification.Error,
+					notification.ActionToast)
+				return err
+			}
 			actionChan <- o.Notification(
 				"error",
 				err.Error(),
@@ -600,7 +623,11 @@ func InitWriter(ctx context.Context, p *ObjectParameter, token tokens.Token) (io
 	if token != nil {
 		if tok, ok := token.(*tokens.BearerToken); !ok {
 			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
-				return nil, errors.New("no bearer token provided")
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return nil, errors.New("no bearer or object session token provided")
+				} else {
+					opts.SetSession(*sessionToken) //the slicer signs each part within the session
+				}
 			} else {
 				opts.SetBearerToken(*tok.BearerToken)
 			}
@@ -623,7 +650,19 @@ func InitWriter(ctx context.Context, p *ObjectParameter, token tokens.Token) (io
 	timestampAttr.SetKey(object.AttributeTimestamp)
 	timestampAttr.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
 
-	p.Attrs = append(p.Attrs, timestampAttr)
+	p.Attrs = append(withFilePath(p.Attrs), timestampAttr)
+	if p.Attrs, err = withExpiration(p, ni); err != nil {
+		return nil, err
+	}
+
+	var dataKey, noncePrefix []byte
+	if p.Encrypt {
+		var encryptionAttrs []object.Attribute
+		if encryptionAttrs, dataKey, noncePrefix, err = newEnvelope(&p.PublicKey); err != nil {
+			return nil, err
+		}
+		p.Attrs = append(p.Attrs, encryptionAttrs...)
+	}
 
 	hdr.SetAttributes(p.Attrs...)
 	plWriter, err := slicer.InitPut(ctx, sdkCli, hdr, gateSigner, opts)
@@ -631,21 +670,80 @@ func InitWriter(ctx context.Context, p *ObjectParameter, token tokens.Token) (io
 		fmt.Println("error creating putter ", err)
 		return nil, err
 	}
+	if p.Encrypt {
+		encWriter, err := newEncryptingWriter(plWriter, dataKey, noncePrefix, defaultEncryptionChunk)
+		if err != nil {
+			return nil, err
+		}
+		p.WriteCloser = encWriter
+		return encWriter, nil
+	}
 	p.WriteCloser = plWriter
 	return plWriter, err
 }
 
+// idWriteCloser is satisfied by both the slicer's writer and the ResumableWriter.
+type idWriteCloser interface {
+	io.WriteCloser
+	ID() oid.ID
+}
+
+// skipStored moves reader past what a previous attempt at a resumable upload has already stored.
+func skipStored(reader io.Reader, offset uint64) error {
+	if offset == 0 {
+		return nil
+	}
+	var err error
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err = seeker.Seek(int64(offset), io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, reader, int64(offset))
+	}
+	return err
+}
+
 func (o ObjectCaller) Create(wg *waitgroup.WG, ctx context.Context, p payload.Parameters, actionChan chan notification.NewNotification, token tokens.Token) error {
 	fmt.Println("beginning to write object")
 	objectParameters, ok := p.(ObjectParameter)
 	if ok {
+		if objectParameters.Deduplicate {
+			existing, found, err := findDuplicate(ctx, objectParameters, token)
+			if err != nil {
+				return err
+			}
+			if found {
+				if err := objectParameters.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, existing); err != nil {
+					fmt.Println("could not emit add update ", err)
+				}
+				actionChan <- o.Notification(
+					"upload skipped",
+					"object "+existing.Id+" already has this content",
+					notification.Success,
+					notification.ActionToast)
+				return nil
+			}
+		}
 		var err error
-		objectWriteCloser, err := InitWriter(ctx, &objectParameters, token)
-		if err != nil {
-			return err
+		var objectWriteCloser io.WriteCloser
+		var offset uint64
+		if objectParameters.UploadKey != "" {
+			resumableWriter, err := InitResumableWriter(ctx, &objectParameters, token, o.Store)
+			if err != nil {
+				return err
+			}
+			objectWriteCloser = resumableWriter
+			offset = resumableWriter.Offset()
+		} else {
+			objectWriteCloser, err = InitWriter(ctx, &objectParameters, token)
+			if err != nil {
+				return err
+			}
 		}
 		if ds, ok := objectParameters.ReadWriter.(*readwriter.DualStream); ok {
 			ds.Writer = objectWriteCloser
+			if err := skipStored(ds.Reader, offset); err != nil {
+				return err
+			}
 		} else {
 			return err
 		}
@@ -678,8 +776,8 @@ func (o ObjectCaller) Create(wg *waitgroup.WG, ctx context.Context, p payload.Pa
 		}
 	}
 
-	var payloadWriter *slicer.PayloadWriter
-	if payloadWriter, ok = objectParameters.WriteCloser.(*slicer.PayloadWriter); !ok {
+	var payloadWriter idWriteCloser
+	if payloadWriter, ok = objectParameters.WriteCloser.(idWriteCloser); !ok {
 		actionChan <- o.Notification(
 			"upload failed",
 			"object "+p.ID()+" failed to upload", //we gleaned the ID during the write initiator.
@@ -723,9 +821,11 @@ type Object struct {
 	Name        string            `json:"name"`
 	Id          string            `json:"id"`
 	ContentType string            `json:"contentType"`
+	FilePath    string            `json:"filePath"`
 	Attributes  map[string]string `json:"attributes"`
 	Size        uint64            `json:"size"`
 	CreatedAt   int64             `json:"CreatedAt"`
+	ExpiresAt   uint64            `json:"expiresAt"` //the epoch the object expires at, zero if it never does
 }
 
 type ObjectRange struct {
@@ -0,0 +1,205 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"log"
+	"sync"
+)
+
+// DefaultReadAhead is how much of an object a RangeReader requests at once when the caller asks for less.
+const DefaultReadAhead = 256 << 10
+
+// RangeReader is an io.ReadSeeker and io.ReaderAt over the payload of a NeoFS object.
+// Data is requested from the network as it is needed, in ranges of at least readAhead bytes,
+// and the last range is kept so that small sequential reads do not each become a request.
+type RangeReader struct {
+	ctx       context.Context
+	pl        *pool.Pool
+	cnrID     cid.ID
+	objID     oid.ID
+	signer    user.Signer
+	prm       client.PrmObjectRange
+	size      uint64
+	offset    int64
+	readAhead uint64
+
+	//the window is shared by every ReadAt, which may run in parallel
+	mutex        sync.Mutex
+	window       []byte
+	windowOffset uint64
+	//fetchRange replaces fetch in tests
+	fetchRange func(offset, length uint64) ([]byte, error)
+
+	//emitter is optional. When set, every range fetched from the network is emitted as an ObjectRange.
+	emitter emitter.Emitter
+}
+
+// NewRangeReader heads the object to learn its size and returns a reader positioned at the start of the payload.
+// A readAhead of zero means exactly what is asked for is requested.
+func NewRangeReader(ctx context.Context, params ObjectParameter, token tokens.Token, readAhead uint64) (*RangeReader, error) {
+	var objID oid.ID
+	if err := objID.DecodeString(params.ID()); err != nil {
+		fmt.Println("wrong object Id", err)
+		return nil, err
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(params.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return nil, err
+	}
+	gA, err := params.ForUser()
+	if err != nil {
+		return nil, err
+	}
+	gateSigner := user.NewAutoIDSigner(gA.PrivateKey().PrivateKey)
+	rangeInit := client.PrmObjectRange{}
+	var prmHead client.PrmObjectHead
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return nil, errors.New("no bearer token provided")
+			} else {
+				rangeInit.WithBearerToken(*tok.BearerToken)
+				prmHead.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			rangeInit.WithBearerToken(*tok.BearerToken)
+			prmHead.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	hdr, err := params.Pool().ObjectHead(ctx, cnrID, objID, gateSigner, prmHead)
+	if err != nil {
+		return nil, err
+	}
+	return &RangeReader{
+		ctx:       ctx,
+		pl:        params.Pool(),
+		cnrID:     cnrID,
+		objID:     objID,
+		signer:    gateSigner,
+		prm:       rangeInit,
+		size:      hdr.PayloadSize(),
+		readAhead: readAhead,
+		emitter:   params.ObjectEmitter,
+	}, nil
+}
+
+// Size is the payload size of the object being read.
+func (r *RangeReader) Size() uint64 {
+	return r.size
+}
+
+// fetch requests a single range from the network.
+func (r *RangeReader) fetch(offset, length uint64) ([]byte, error) {
+	objRangeReader, err := r.pl.ObjectRangeInit(r.ctx, r.cnrID, r.objID, offset, length, r.signer, r.prm)
+	if err != nil {
+		log.Println("error creating object range reader ", err)
+		return nil, err
+	}
+	buf := make([]byte, length)
+	n, err := io.ReadFull(objRangeReader, buf)
+	if closeErr := objRangeReader.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf = buf[:n]
+	if r.emitter != nil {
+		if err := r.emitter.Emit(r.ctx, emitter.ObjectRangeUpdate, ObjectRange{Offset: offset, Limit: length, Data: buf}); err != nil {
+			fmt.Println("could not emit range ", err)
+		}
+	}
+	return buf, nil
+}
+
+// ReadAt reads len(p) bytes from off. It does not move the offset used by Read and Seek and may be called in parallel.
+func (r *RangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if uint64(off) >= r.size {
+		return 0, io.EOF
+	}
+	start := uint64(off)
+	want := uint64(len(p))
+	if remaining := r.size - start; want > remaining {
+		want = remaining
+	}
+	r.mutex.Lock()
+	window, windowOffset := r.window, r.windowOffset
+	r.mutex.Unlock()
+	if start < windowOffset || start+want > windowOffset+uint64(len(window)) {
+		length := want
+		if length < r.readAhead {
+			length = r.readAhead
+		}
+		if remaining := r.size - start; length > remaining {
+			length = remaining
+		}
+		fetch := r.fetchRange
+		if fetch == nil {
+			fetch = r.fetch
+		}
+		//the lock is not held while fetching so other reads are not held up by this one
+		data, err := fetch(start, length)
+		if err != nil {
+			return 0, err
+		}
+		window, windowOffset = data, start
+		r.mutex.Lock()
+		r.window, r.windowOffset = window, windowOffset
+		r.mutex.Unlock()
+	}
+	n := copy(p, window[start-windowOffset:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *RangeReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		return n, nil
+	}
+	return n, err
+}
+
+func (r *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = int64(r.size) + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = abs
+	return abs, nil
+}
+
+// Close drops the read-ahead buffer. Every range request is closed as soon as it is read so nothing else is held open.
+func (r *RangeReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.window = nil
+	return nil
+}
@@ -0,0 +1,113 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeRanges serves ranges of payload and counts how many were requested.
+type fakeRanges struct {
+	payload []byte
+	mutex   sync.Mutex
+	fetched [][2]uint64
+}
+
+func (f *fakeRanges) fetch(offset, length uint64) ([]byte, error) {
+	f.mutex.Lock()
+	f.fetched = append(f.fetched, [2]uint64{offset, length})
+	f.mutex.Unlock()
+	return append([]byte{}, f.payload[offset:offset+length]...), nil
+}
+
+func (f *fakeRanges) requests() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.fetched)
+}
+
+func testRangeReader(payload []byte, readAhead uint64) (*RangeReader, *fakeRanges) {
+	source := &fakeRanges{payload: payload}
+	return &RangeReader{size: uint64(len(payload)), readAhead: readAhead, fetchRange: source.fetch}, source
+}
+
+func TestRangeReaderWindow(t *testing.T) {
+	payload := []byte("abcdefghijklmnopqrst")
+	r, source := testRangeReader(payload, 8)
+	p := make([]byte, 2)
+
+	if _, err := r.ReadAt(p, 0); err != nil || string(p) != "ab" {
+		t.Fatalf("ReadAt(0) = %q, %v", p, err)
+	}
+	if _, err := r.ReadAt(p, 4); err != nil || string(p) != "ef" {
+		t.Fatalf("ReadAt(4) = %q, %v", p, err)
+	}
+	if source.requests() != 1 {
+		t.Fatalf("a read inside the window should not fetch, %d requests", source.requests())
+	}
+	if source.fetched[0] != [2]uint64{0, 8} {
+		t.Fatalf("a small read should fetch the read ahead, fetched %v", source.fetched[0])
+	}
+
+	p = make([]byte, 4)
+	if _, err := r.ReadAt(p, 6); err != nil || string(p) != "ghij" {
+		t.Fatalf("ReadAt(6) = %q, %v", p, err)
+	}
+	if source.requests() != 2 || source.fetched[1] != [2]uint64{6, 8} {
+		t.Fatalf("a read past the window should fetch from its offset, fetched %v", source.fetched)
+	}
+	if _, err := r.ReadAt(p[:2], 1); err != nil || string(p[:2]) != "bc" {
+		t.Fatalf("ReadAt(1) = %q, %v", p[:2], err)
+	}
+	if source.requests() != 3 {
+		t.Fatalf("a read before the window should fetch, %d requests", source.requests())
+	}
+}
+
+func TestRangeReaderEnd(t *testing.T) {
+	payload := []byte("abcdefghijklmnopqrst")
+	r, _ := testRangeReader(payload, 8)
+	p := make([]byte, 5)
+	n, err := r.ReadAt(p, 18)
+	if n != 2 || err != io.EOF || string(p[:n]) != "st" {
+		t.Fatalf("ReadAt past the end = %d %q, %v", n, p[:n], err)
+	}
+	if _, err := r.ReadAt(p, 20); err != io.EOF {
+		t.Fatalf("ReadAt at the end = %v, want EOF", err)
+	}
+
+	r, source := testRangeReader(payload, 8)
+	var all bytes.Buffer
+	//small sequential reads, the way a decoder reads
+	if _, err := io.CopyBuffer(struct{ io.Writer }{&all}, struct{ io.Reader }{r}, make([]byte, 2)); err != nil || !bytes.Equal(all.Bytes(), payload) {
+		t.Fatalf("read %q, %v", all.Bytes(), err)
+	}
+	if source.requests() != 3 {
+		t.Fatalf("reading %d bytes with a read ahead of 8 took %d requests", len(payload), source.requests())
+	}
+}
+
+func TestRangeReaderParallelReadAt(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 10)
+	r, _ := testRangeReader(payload, 16)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for off := i; off+5 <= len(payload); off += 7 {
+				p := make([]byte, 5)
+				if _, err := r.ReadAt(p, int64(off)); err != nil {
+					t.Errorf("ReadAt(%d): %v", off, err)
+					return
+				}
+				if !bytes.Equal(p, payload[off:off+5]) {
+					t.Errorf("ReadAt(%d) = %q, want %q", off, p, payload[off:off+5])
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
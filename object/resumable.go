@@ -0,0 +1,425 @@
+package object
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/database"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object/slicer"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sdk-go/version"
+	"github.com/nspcc-dev/tzhash/tz"
+	"hash"
+	"strconv"
+	"time"
+)
+
+// UploadChild is a child object of a split upload that has already been stored on the network.
+type UploadChild struct {
+	Id              string `json:"id"`
+	Size            uint32 `json:"size"`
+	HomomorphicHash []byte `json:"homomorphicHash,omitempty"`
+}
+
+// UploadState is the persisted progress of a resumable upload. It holds enough of the split chain
+// to carry on from the last child object that was stored and then write the link object.
+type UploadState struct {
+	Key          string        `json:"key"`
+	ContainerID  string        `json:"containerID"`
+	Header       []byte        `json:"header"` //the parent header template, as it was when the upload began
+	Offset       uint64        `json:"offset"` //bytes of payload already stored in child objects
+	PayloadLimit uint64        `json:"payloadLimit"`
+	Homomorphic  bool          `json:"homomorphic"`
+	FirstID      string        `json:"firstID"`
+	Children     []UploadChild `json:"children"`
+	HashState    []byte        `json:"hashState"` //sha256 state of the payload stored so far
+	UpdatedAt    int64         `json:"updatedAt"`
+}
+
+// LoadUploadState retrieves the progress of an unfinished upload from the store.
+func LoadUploadState(store database.Store, key string) (UploadState, error) {
+	var state UploadState
+	if store == nil {
+		return state, errors.New(utils.ErrorNoDatabase)
+	}
+	byt, err := store.Select(database.UploadBucket, key)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(byt, &state)
+	return state, err
+}
+
+// UploadOffset returns how many bytes of an upload have already been stored, e.g. to show how far it got before resuming it.
+// An unknown key is not an error, the upload simply starts from zero.
+func UploadOffset(store database.Store, key string) (uint64, error) {
+	state, err := LoadUploadState(store, key)
+	if err != nil {
+		if err.Error() == database.ErrorNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return state.Offset, nil
+}
+
+// AbortUpload forgets the progress of an upload. Child objects already stored are left to expire or be collected.
+func AbortUpload(store database.Store, key string) error {
+	if store == nil {
+		return errors.New(utils.ErrorNoDatabase)
+	}
+	return store.Delete(database.UploadBucket, key)
+}
+
+// ResumableWriter splits a payload into child objects itself (rather than through the slicer) so that
+// after every stored child the progress can be persisted and a later upload with the same key can continue.
+type ResumableWriter struct {
+	ctx         context.Context
+	store       database.Store
+	stream      slicer.ObjectWriter
+	signer      user.Signer
+	prm         client.PrmObjectPutInit
+	session     *session.Object //set when uploading within an object session rather than with a bearer token
+	state       UploadState
+	header      object.Object
+	child       object.Object //template every child object is created from
+	payloadHash hash.Hash
+	buf         []byte
+	id          oid.ID
+}
+
+// InitResumableWriter is the resumable counterpart of InitWriter. If the store holds progress for p.UploadKey, the writer
+// carries on from it and Offset reports how many bytes of the source have to be skipped.
+func InitResumableWriter(ctx context.Context, p *ObjectParameter, token tokens.Token, store database.Store) (*ResumableWriter, error) {
+	if p.UploadKey == "" {
+		return nil, errors.New("no upload key")
+	}
+	if store == nil {
+		return nil, errors.New(utils.ErrorNoDatabase)
+	}
+	if p.Encrypt {
+		return nil, errors.New("encrypted uploads cannot be resumed")
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return nil, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return nil, err
+	}
+	sdkCli, err := p.Pool().RawClient()
+	if err != nil {
+		return nil, err
+	}
+	ni, err := sdkCli.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return nil, fmt.Errorf("network info: %w", err)
+	}
+	w := &ResumableWriter{
+		ctx:         ctx,
+		store:       store,
+		stream:      sdkCli,
+		signer:      user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey),
+		payloadHash: sha256.New(),
+	}
+	owner := user.ResolveFromECDSAPublicKey(p.PublicKey)
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return nil, errors.New("no bearer or object session token provided")
+				} else {
+					//as the slicer does, every object is put within the session and names it
+					w.prm.WithinSession(*sessionToken)
+					w.session = sessionToken
+					owner = sessionToken.Issuer()
+				}
+			} else {
+				w.prm.WithBearerToken(*tok.BearerToken)
+				owner = tok.BearerToken.Issuer() //token issuer is the container owner
+			}
+		} else {
+			w.prm.WithBearerToken(*tok.BearerToken)
+			owner = tok.BearerToken.Issuer()
+		}
+	}
+
+	resumed, err := w.restore(p.UploadKey, cnrID)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		var timestampAttr object.Attribute
+		timestampAttr.SetKey(object.AttributeTimestamp)
+		timestampAttr.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
+		p.Attrs = append(withFilePath(p.Attrs), timestampAttr)
+		if p.Attrs, err = withExpiration(p, ni); err != nil {
+			return nil, err
+		}
+
+		currentVersion := version.Current()
+		w.header.SetVersion(&currentVersion)
+		w.header.SetContainerID(cnrID)
+		w.header.SetType(object.TypeRegular)
+		w.header.SetOwnerID(&owner)
+		w.header.SetCreationEpoch(ni.CurrentEpoch())
+		w.header.SetAttributes(p.Attrs...)
+		if w.state.Header, err = w.header.Marshal(); err != nil {
+			return nil, err
+		}
+		w.state.Key = p.UploadKey
+		w.state.ContainerID = cnrID.String()
+		w.state.PayloadLimit = ni.MaxObjectSize()
+		w.state.Homomorphic = !ni.HomomorphicHashingDisabled()
+	}
+	if w.session != nil {
+		//a resumed upload carries on within the session it was given this time
+		w.header.SetSessionToken(w.session)
+	}
+	w.initChild(cnrID, ni.CurrentEpoch())
+
+	p.WriteCloser = w
+	return w, nil
+}
+
+// restore carries on from the progress stored under key, if there is any. Progress that cannot be restored is forgotten,
+// it would fail every later attempt too, and the upload starts afresh.
+func (w *ResumableWriter) restore(key string, cnrID cid.ID) (bool, error) {
+	state, err := LoadUploadState(w.store, key)
+	if err != nil {
+		if err.Error() == database.ErrorNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if state.ContainerID != cnrID.String() {
+		return false, fmt.Errorf("upload %s belongs to container %s", key, state.ContainerID)
+	}
+	//the template has no ID yet, which Unmarshal refuses, so it is read as the raw message
+	if err := w.header.ToV2().Unmarshal(state.Header); err != nil {
+		fmt.Println("could not restore upload header ", err)
+		return false, AbortUpload(w.store, key)
+	}
+	if err := w.payloadHash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state.HashState); err != nil {
+		fmt.Println("could not restore upload checksum ", err)
+		w.header, w.payloadHash = object.Object{}, sha256.New()
+		return false, AbortUpload(w.store, key)
+	}
+	w.state = state
+	return true, nil
+}
+
+// initChild prepares the template every child object is created from.
+func (w *ResumableWriter) initChild(cnrID cid.ID, epoch uint64) {
+	currentVersion := version.Current()
+	w.child.SetVersion(&currentVersion)
+	w.child.SetContainerID(cnrID)
+	w.child.SetCreationEpoch(epoch)
+	w.child.SetType(object.TypeRegular)
+	w.child.SetOwnerID(w.header.OwnerID())
+	w.child.SetSessionToken(w.session)
+}
+
+// Offset is the number of payload bytes that were stored before this writer was created.
+func (w *ResumableWriter) Offset() uint64 {
+	return w.state.Offset
+}
+
+// Write buffers the payload and stores a child object every time more than a full object's worth is held.
+// A child is only flushed once it is known that more data follows, otherwise a small payload would be split needlessly.
+func (w *ResumableWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for uint64(len(w.buf)) > w.state.PayloadLimit {
+		if err := w.writeChild(w.buf[:w.state.PayloadLimit], false); err != nil {
+			return 0, err
+		}
+		w.buf = append([]byte{}, w.buf[w.state.PayloadLimit:]...)
+	}
+	return len(p), nil
+}
+
+// Close stores the remaining payload, the link object and then forgets the upload progress.
+func (w *ResumableWriter) Close() error {
+	if len(w.state.Children) == 0 {
+		//everything fitted in a single object, no split chain required
+		w.payloadHash.Write(w.buf)
+		obj := w.header
+		if err := w.finaliseParent(&obj, w.buf); err != nil {
+			return err
+		}
+		if _, err := w.put(obj, w.buf); err != nil {
+			return err
+		}
+	} else if err := w.writeChild(w.buf, true); err != nil {
+		return err
+	}
+	w.buf = nil
+	if err := w.store.Delete(database.UploadBucket, w.state.Key); err != nil {
+		fmt.Println("could not remove finished upload ", err)
+	}
+	return nil
+}
+
+// ID is the identifier of the uploaded object. It is only set after a successful Close.
+func (w *ResumableWriter) ID() oid.ID {
+	return w.id
+}
+
+// finaliseParent sets the payload dependent fields of the parent header and signs it.
+func (w *ResumableWriter) finaliseParent(hdr *object.Object, lastPayload []byte) error {
+	var cs checksum.Checksum
+	var sum [sha256.Size]byte
+	copy(sum[:], w.payloadHash.Sum(nil))
+	cs.SetSHA256(sum)
+	hdr.SetPayloadChecksum(cs)
+	if w.state.Homomorphic {
+		hashes := make([][]byte, 0, len(w.state.Children)+1)
+		for _, c := range w.state.Children {
+			hashes = append(hashes, c.HomomorphicHash)
+		}
+		last := tz.Sum(lastPayload)
+		hashes = append(hashes, last[:])
+		homo, err := tz.Concat(hashes)
+		if err != nil {
+			return fmt.Errorf("homomorphic hash: %w", err)
+		}
+		var homoSum [tz.Size]byte
+		copy(homoSum[:], homo)
+		cs.SetTillichZemor(homoSum)
+		hdr.SetPayloadHomomorphicHash(cs)
+	}
+	hdr.SetPayloadSize(w.state.Offset + uint64(len(lastPayload)))
+	if err := hdr.SetIDWithSignature(w.signer); err != nil {
+		return err
+	}
+	w.id, _ = hdr.ID()
+	return nil
+}
+
+// writeChild stores the next element of the split chain and persists the progress.
+func (w *ResumableWriter) writeChild(payload []byte, last bool) error {
+	obj := w.child
+	if len(w.state.Children) == 0 {
+		//the first child carries the parent header
+		obj.SetParent(&w.header)
+	} else {
+		var first, previous oid.ID
+		if err := first.DecodeString(w.state.FirstID); err != nil {
+			return err
+		}
+		if err := previous.DecodeString(w.state.Children[len(w.state.Children)-1].Id); err != nil {
+			return err
+		}
+		obj.SetFirstID(first)
+		obj.SetPreviousID(previous)
+	}
+	w.payloadHash.Write(payload)
+	if last {
+		parent := w.header
+		if err := w.finaliseParent(&parent, payload); err != nil {
+			return err
+		}
+		obj.SetParentID(w.id)
+		obj.SetParent(&parent)
+	}
+	id, err := w.put(obj, payload)
+	if err != nil {
+		return err
+	}
+	child := UploadChild{Id: id.String(), Size: uint32(len(payload))}
+	if w.state.Homomorphic {
+		sum := tz.Sum(payload)
+		child.HomomorphicHash = sum[:]
+	}
+	if len(w.state.Children) == 0 {
+		w.state.FirstID = id.String()
+	}
+	w.state.Children = append(w.state.Children, child)
+	if last {
+		return w.writeLink(obj)
+	}
+	w.state.Offset += uint64(len(payload))
+	return w.persist()
+}
+
+// writeLink stores the link object listing every child, which makes the parent object readable as a whole.
+func (w *ResumableWriter) writeLink(lastChild object.Object) error {
+	measured := make([]object.MeasuredObject, 0, len(w.state.Children))
+	for _, c := range w.state.Children {
+		var id oid.ID
+		if err := id.DecodeString(c.Id); err != nil {
+			return err
+		}
+		var m object.MeasuredObject
+		m.SetObjectID(id)
+		m.SetObjectSize(c.Size)
+		measured = append(measured, m)
+	}
+	var link object.Link
+	link.SetObjects(measured)
+	obj := lastChild
+	obj.WriteLink(link)
+	obj.ResetPreviousID()
+	obj.ResetID()
+	obj.SetSignature(nil)
+	if _, err := w.put(obj, obj.Payload()); err != nil {
+		return fmt.Errorf("write linking object: %w", err)
+	}
+	return nil
+}
+
+// put calculates the verification fields of an object and sends it with its payload.
+func (w *ResumableWriter) put(obj object.Object, payload []byte) (oid.ID, error) {
+	id, isSet := obj.ID()
+	if !isSet || obj.Signature() == nil {
+		obj.SetPayloadChecksum(object.CalculatePayloadChecksum(payload))
+		if w.state.Homomorphic {
+			var cs checksum.Checksum
+			cs.SetTillichZemor(tz.Sum(payload))
+			obj.SetPayloadHomomorphicHash(cs)
+		}
+		obj.SetPayloadSize(uint64(len(payload)))
+		if err := obj.SetIDWithSignature(w.signer); err != nil {
+			return id, err
+		}
+		id, _ = obj.ID()
+	}
+	stream, err := w.stream.ObjectPutInit(w.ctx, obj, w.signer, w.prm)
+	if err != nil {
+		return id, fmt.Errorf("init data stream for next object: %w", err)
+	}
+	if _, err := stream.Write(payload); err != nil {
+		return id, fmt.Errorf("write object payload: %w", err)
+	}
+	if err := stream.Close(); err != nil {
+		return id, fmt.Errorf("finish object stream: %w", err)
+	}
+	return id, nil
+}
+
+func (w *ResumableWriter) persist() error {
+	hashState, err := w.payloadHash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	w.state.HashState = hashState
+	w.state.UpdatedAt = time.Now().Unix()
+	byt, err := json.Marshal(w.state)
+	if err != nil {
+		return err
+	}
+	return w.store.Update(database.UploadBucket, w.state.Key, byt)
+}
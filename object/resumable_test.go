@@ -0,0 +1,210 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/configwizard/sdk/database"
+	"github.com/google/uuid"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// fakeObjectWriter stores what is put in memory. The put numbered failAt fails, as if the node went away.
+type fakeObjectWriter struct {
+	headers  []object.Object
+	payloads [][]byte
+	puts     int
+	failAt   int
+}
+
+func (f *fakeObjectWriter) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	f.puts++
+	if f.puts == f.failAt {
+		return nil, errors.New("node unavailable")
+	}
+	f.headers = append(f.headers, hdr)
+	return &fakePayloadWriter{f: f}, nil
+}
+
+type fakePayloadWriter struct {
+	f   *fakeObjectWriter
+	buf []byte
+}
+
+func (w *fakePayloadWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *fakePayloadWriter) Close() error {
+	w.f.payloads = append(w.f.payloads, w.buf)
+	return nil
+}
+
+func (w *fakePayloadWriter) GetResult() client.ResObjectPut {
+	return client.ResObjectPut{}
+}
+
+// testResumableWriter is set up the way InitResumableWriter does it, without a network to ask for its limits.
+func testResumableWriter(t *testing.T, store database.Store, stream *fakeObjectWriter, limit uint64) *ResumableWriter {
+	key, err := keys.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnrID := cid.ID(sha256.Sum256([]byte("resumable")))
+	w := &ResumableWriter{
+		ctx:         context.Background(),
+		store:       store,
+		stream:      stream,
+		signer:      user.NewAutoIDSignerRFC6979(key.PrivateKey),
+		payloadHash: sha256.New(),
+	}
+	resumed, err := w.restore("upload", cnrID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed {
+		owner := w.signer.UserID()
+		w.header.SetContainerID(cnrID)
+		w.header.SetOwnerID(&owner)
+		if w.state.Header, err = w.header.Marshal(); err != nil {
+			t.Fatal(err)
+		}
+		w.state.Key = "upload"
+		w.state.ContainerID = cnrID.String()
+		w.state.PayloadLimit = limit
+	}
+	w.initChild(cnrID, 1)
+	return w
+}
+
+// storedPayload is the payload of every child object, in order, without the link object.
+func storedPayload(stream *fakeObjectWriter) []byte {
+	var payload []byte
+	for _, p := range stream.payloads[:len(stream.payloads)-1] {
+		payload = append(payload, p...)
+	}
+	return payload
+}
+
+func TestResumableWriterPersistsProgress(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	stream := &fakeObjectWriter{}
+	w := testResumableWriter(t, store, stream, 4)
+	data := []byte("0123456789")
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadUploadState(store, "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Offset != 8 || len(state.Children) != 2 {
+		t.Fatalf("persisted offset %d with %d children, want 8 with 2", state.Offset, len(state.Children))
+	}
+	if offset, err := UploadOffset(store, "upload"); err != nil || offset != 8 {
+		t.Fatalf("UploadOffset = %d, %v", offset, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.headers) != 4 {
+		t.Fatalf("stored %d objects, want 3 children and a link", len(stream.headers))
+	}
+	if !bytes.Equal(storedPayload(stream), data) {
+		t.Fatalf("stored payload %q, want %q", storedPayload(stream), data)
+	}
+	if _, err := LoadUploadState(store, "upload"); err == nil {
+		t.Fatal("a finished upload should be forgotten")
+	}
+	if offset, err := UploadOffset(store, "upload"); err != nil || offset != 0 {
+		t.Fatalf("UploadOffset of a finished upload = %d, %v", offset, err)
+	}
+}
+
+func TestResumableWriterResumes(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	stream := &fakeObjectWriter{failAt: 2}
+	data := []byte("0123456789")
+	w := testResumableWriter(t, store, stream, 4)
+	if _, err := w.Write(data); err == nil {
+		t.Fatal("the second child should fail")
+	}
+
+	resumed := testResumableWriter(t, store, stream, 4)
+	if resumed.Offset() != 4 {
+		t.Fatalf("resumed from %d, want 4", resumed.Offset())
+	}
+	if _, err := resumed.Write(data[resumed.Offset():]); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(storedPayload(stream), data) {
+		t.Fatalf("stored payload %q, want %q", storedPayload(stream), data)
+	}
+	last := stream.headers[len(stream.headers)-2]
+	parent := last.Parent()
+	if parent == nil {
+		t.Fatal("the last child should carry the parent header")
+	}
+	if parent.PayloadSize() != uint64(len(data)) {
+		t.Fatalf("parent payload size %d, want %d", parent.PayloadSize(), len(data))
+	}
+	cs, ok := parent.PayloadChecksum()
+	sum := sha256.Sum256(data)
+	if !ok || !bytes.Equal(cs.Value(), sum[:]) {
+		t.Fatal("the parent checksum should cover the payload of both attempts")
+	}
+}
+
+func TestResumableWriterForgetsCorruptProgress(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("resumable")))
+	if err := store.Create(database.UploadBucket, "upload", []byte(`{"key":"upload","containerID":"`+cnrID.String()+`","header":"bm90IGEgaGVhZGVy","offset":8}`)); err != nil {
+		t.Fatal(err)
+	}
+	w := testResumableWriter(t, store, &fakeObjectWriter{}, 4)
+	if w.Offset() != 0 {
+		t.Fatalf("corrupt progress should start afresh, offset %d", w.Offset())
+	}
+	if _, err := LoadUploadState(store, "upload"); err == nil {
+		t.Fatal("corrupt progress should be forgotten")
+	}
+}
+
+func TestResumableWriterWithinSession(t *testing.T) {
+	store := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	stream := &fakeObjectWriter{}
+	w := testResumableWriter(t, store, stream, 4)
+	//as InitResumableWriter does for an object session token
+	var sess session.Object
+	sess.SetID(uuid.New())
+	w.session = &sess
+	w.header.SetSessionToken(w.session)
+	w.initChild(cid.ID(sha256.Sum256([]byte("resumable"))), 1)
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i, hdr := range stream.headers {
+		if hdr.SessionToken() == nil || hdr.SessionToken().ID() != sess.ID() {
+			t.Fatalf("object %d was not put within the session", i)
+		}
+	}
+	last := stream.headers[len(stream.headers)-2]
+	if last.Parent() == nil || last.Parent().SessionToken() == nil {
+		t.Fatal("the parent header should name the session")
+	}
+}
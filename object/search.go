@@ -0,0 +1,171 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// SearchFilter is a single condition on an object header. Key is an attribute name (or a reserved $Object: header)
+// and Match is one of the NeoFS match types. MatchNotPresent ignores Value.
+type SearchFilter struct {
+	Key   string                 `json:"key"`
+	Value string                 `json:"value"`
+	Match object.SearchMatchType `json:"match"`
+}
+
+// SearchQuery is a set of filters that must all match (AND).
+// Offset skips that many matches and Limit stops after that many have been emitted, zero meaning no limit.
+type SearchQuery struct {
+	Filters         []SearchFilter `json:"filters"`
+	IncludeChildren bool           `json:"includeChildren"` //by default only root objects are returned, not the parts of split objects
+	Offset          int            `json:"offset"`
+	Limit           int            `json:"limit"`
+}
+
+// SearchPage describes what a Search emitted, so the caller can ask for the next page.
+type SearchPage struct {
+	Emitted    int  `json:"emitted"`
+	NextOffset int  `json:"nextOffset"`
+	More       bool `json:"more"`
+}
+
+// TimestampBetween filters on the Timestamp attribute, inclusive of both ends. A zero time leaves that end open.
+func TimestampBetween(from, to time.Time) []SearchFilter {
+	var filters []SearchFilter
+	if !from.IsZero() {
+		filters = append(filters, SearchFilter{Key: object.AttributeTimestamp, Value: strconv.FormatInt(from.Unix(), 10), Match: object.MatchNumGE})
+	}
+	if !to.IsZero() {
+		filters = append(filters, SearchFilter{Key: object.AttributeTimestamp, Value: strconv.FormatInt(to.Unix(), 10), Match: object.MatchNumLE})
+	}
+	return filters
+}
+
+// searchFilters validates the query and converts it into NeoFS search filters.
+func (q SearchQuery) searchFilters() (object.SearchFilters, error) {
+	if q.Offset < 0 || q.Limit < 0 {
+		return nil, errors.New("offset and limit cannot be negative")
+	}
+	filters := object.NewSearchFilters()
+	if !q.IncludeChildren {
+		filters.AddRootFilter()
+	}
+	for _, f := range q.Filters {
+		if f.Key == "" {
+			return nil, errors.New("search filter has no key")
+		}
+		switch f.Match {
+		case object.MatchStringEqual, object.MatchStringNotEqual, object.MatchCommonPrefix:
+		case object.MatchNotPresent:
+			f.Value = ""
+		case object.MatchNumGT, object.MatchNumGE, object.MatchNumLT, object.MatchNumLE:
+			if _, ok := new(big.Int).SetString(f.Value, 10); !ok {
+				return nil, fmt.Errorf("search filter %s needs an integer value, got %q", f.Key, f.Value)
+			}
+		default:
+			return nil, fmt.Errorf("search filter %s has unsupported match type %d", f.Key, f.Match)
+		}
+		filters.AddFilter(f.Key, f.Value, f.Match)
+	}
+	return filters, nil
+}
+
+// Search finds every object in the container that matches all the filters of the query and emits each head
+// through the ObjectEmitter as an ObjectAddUpdate, the same as Head does.
+func (o *ObjectCaller) Search(ctx context.Context, p ObjectParameter, query SearchQuery, token tokens.Token) (SearchPage, error) {
+	return search(ctx, p, query, token, func(localObject Object) error {
+		if p.ObjectEmitter == nil {
+			return nil
+		}
+		return p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, localObject)
+	})
+}
+
+// search runs the query and hands each matching head to found.
+func search(ctx context.Context, p ObjectParameter, query SearchQuery, token tokens.Token, found func(Object) error) (SearchPage, error) {
+	var page SearchPage
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return page, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return page, err
+	}
+	filters, err := query.searchFilters()
+	if err != nil {
+		return page, err
+	}
+	prmSearch := client.PrmObjectSearch{}
+	var prmHead client.PrmObjectHead
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				if sessionToken, ok := tokens.ObjectSession(token); !ok {
+					return page, errors.New(utils.ErrorNoToken)
+				} else {
+					prmSearch.WithinSession(*sessionToken)
+					prmHead.WithinSession(*sessionToken)
+				}
+			} else {
+				prmSearch.WithBearerToken(*tok.BearerToken)
+				prmHead.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			prmSearch.WithBearerToken(*tok.BearerToken)
+			prmHead.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	prmSearch.SetFilters(filters)
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	init, err := p.Pool().ObjectSearchInit(ctx, cnrID, gateSigner, prmSearch)
+	if err != nil {
+		return page, err
+	}
+	//neofs has no server side paging, so we skip until the offset and stop one past the limit to know if there is more.
+	skipped := 0
+	var iterationError error
+	if err = init.Iterate(func(id oid.ID) bool {
+		if skipped < query.Offset {
+			skipped++
+			return false
+		}
+		if query.Limit > 0 && page.Emitted == query.Limit {
+			page.More = true
+			return true
+		}
+		hdr, err := p.Pool().ObjectHead(ctx, cnrID, id, gateSigner, prmHead)
+		if err != nil {
+			iterationError = err
+			return true
+		}
+		localObject, err := headerToObject(cnrID, id, hdr)
+		if err != nil {
+			iterationError = err
+			return true
+		}
+		if err := found(localObject); err != nil {
+			iterationError = err
+			return true
+		}
+		page.Emitted++
+		return false
+	}); err != nil {
+		return page, err
+	}
+	page.NextOffset = query.Offset + page.Emitted
+	return page, iterationError
+}
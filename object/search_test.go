@@ -0,0 +1,110 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+func TestSearchFilters(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   SearchQuery
+		wantErr bool
+		want    []SearchFilter //the filters after the root filter, if there is one
+		root    bool
+	}{
+		{
+			name:  "no filters",
+			query: SearchQuery{},
+			root:  true,
+		},
+		{
+			name:  "children included",
+			query: SearchQuery{IncludeChildren: true},
+		},
+		{
+			name: "filters kept in order",
+			query: SearchQuery{Filters: []SearchFilter{
+				{Key: object.AttributeFilePath, Value: "photos/", Match: object.MatchCommonPrefix},
+				{Key: object.AttributeTimestamp, Value: "1700000000", Match: object.MatchNumGE},
+			}},
+			root: true,
+			want: []SearchFilter{
+				{Key: object.AttributeFilePath, Value: "photos/", Match: object.MatchCommonPrefix},
+				{Key: object.AttributeTimestamp, Value: "1700000000", Match: object.MatchNumGE},
+			},
+		},
+		{
+			name:  "not present ignores the value",
+			query: SearchQuery{IncludeChildren: true, Filters: []SearchFilter{{Key: "Expired", Value: "anything", Match: object.MatchNotPresent}}},
+			want:  []SearchFilter{{Key: "Expired", Match: object.MatchNotPresent}},
+		},
+		{
+			name:  "large numbers",
+			query: SearchQuery{IncludeChildren: true, Filters: []SearchFilter{{Key: "Size", Value: "123456789012345678901234567890", Match: object.MatchNumLT}}},
+			want:  []SearchFilter{{Key: "Size", Value: "123456789012345678901234567890", Match: object.MatchNumLT}},
+		},
+		{
+			name:    "empty key",
+			query:   SearchQuery{Filters: []SearchFilter{{Key: "", Value: "a", Match: object.MatchStringEqual}}},
+			wantErr: true,
+		},
+		{
+			name:    "non-integer numeric value",
+			query:   SearchQuery{Filters: []SearchFilter{{Key: object.AttributeTimestamp, Value: "yesterday", Match: object.MatchNumGT}}},
+			wantErr: true,
+		},
+		{
+			name:    "decimal numeric value",
+			query:   SearchQuery{Filters: []SearchFilter{{Key: object.AttributeTimestamp, Value: "1.5", Match: object.MatchNumLE}}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported match type",
+			query:   SearchQuery{Filters: []SearchFilter{{Key: "a", Value: "b", Match: object.MatchUnknown}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative offset",
+			query:   SearchQuery{Offset: -1},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   SearchQuery{Limit: -1},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filters, err := c.query.searchFilters()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d filters", len(filters))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.root {
+				if len(filters) == 0 || filters[0].Header() != object.FilterRoot {
+					t.Fatal("expected the root filter first")
+				}
+				filters = filters[1:]
+			}
+			if len(filters) != len(c.want) {
+				t.Fatalf("expected %d filters, got %d", len(c.want), len(filters))
+			}
+			for i, want := range c.want {
+				if filters[i].Header() == object.FilterRoot {
+					t.Fatal("the root filter should only be added without IncludeChildren")
+				}
+				if filters[i].Header() != want.Key || filters[i].Value() != want.Value || filters[i].Operation() != want.Match {
+					t.Errorf("filter %d is %s %s %v, want %s %s %v", i, filters[i].Header(), filters[i].Value(), filters[i].Operation(), want.Key, want.Value, want.Match)
+				}
+			}
+		})
+	}
+}
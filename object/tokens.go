@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"github.com/configwizard/sdk/config"
 	"github.com/configwizard/sdk/payload"
+	"github.com/configwizard/sdk/tokens"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
@@ -20,9 +21,41 @@ func ContainerBearerToken(p payload.Parameters, issuerKey keys.PublicKey, nodes
 	if err := cnrID.DecodeString(p.ID()); err != nil {
 		return bearer.Token{}, err
 	}
-	return ObjectBearerToken(cnrID, p, issuerKey, nodes)
+	operation := p.Operation()
+	if operation == eacl.OperationUnknown {
+		//container actions that use a bearer token list the container
+		operation = eacl.OperationSearch
+	}
+	return operationsBearerToken(cnrID, p, tokens.RequiredOperations(operation), issuerKey)
 }
+
+// ObjectBearerToken allows the gate key only the operations the action p describes needs, see RequiredOperations,
+// so a leaked gate key gives away as little as possible.
 func ObjectBearerToken(cnrID cid.ID, p payload.Parameters, issuerKey keys.PublicKey, nodes []config.Peer) (bearer.Token, error) {
+	return operationsBearerToken(cnrID, p, RequiredOperations(p), issuerKey)
+}
+
+// RequiredOperations returns the operations a token must allow for the action p describes: those of
+// tokens.RequiredOperations for p.Operation(), and those the object actions add, e.g. Deduplicate searches the container
+// and heads what it finds before an upload and Delete searches for the locks protecting an object and gets them.
+func RequiredOperations(p payload.Parameters) []eacl.Operation {
+	operations := tokens.RequiredOperations(p.Operation())
+	o, ok := p.(ObjectParameter)
+	if !ok {
+		return operations
+	}
+	switch o.Operation() {
+	case eacl.OperationPut:
+		if o.Deduplicate {
+			operations = append(operations, eacl.OperationSearch, eacl.OperationHead)
+		}
+	case eacl.OperationDelete:
+		operations = append(operations, eacl.OperationSearch, eacl.OperationGet)
+	}
+	return operations
+}
+
+func operationsBearerToken(cnrID cid.ID, p payload.Parameters, operations []eacl.Operation, issuerKey keys.PublicKey) (bearer.Token, error) {
 	gA, err := p.ForUser()
 	if err != nil {
 		return bearer.Token{}, err
@@ -45,18 +78,28 @@ func ObjectBearerToken(cnrID cid.ID, p payload.Parameters, issuerKey keys.Public
 	bearerToken.SetIat(netInfo.CurrentEpoch())
 	bearerToken.SetNbf(netInfo.CurrentEpoch())
 	bearerToken.SetExp(netInfo.CurrentEpoch() + p.Epoch()) // or particular exp value
+	tab := operationsTable(cnrID, operations, gA.PrivateKey().PrivateKey.PublicKey)
+	bearerToken.SetEACLTable(tab)
+	var issuer user.ID
+	issuer = user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(issuerKey))
+	bearerToken.SetIssuer(issuer)
+	return bearerToken, nil
+}
+
+// operationsTable allows gateKey operations in the container and denies everyone else everything.
+func operationsTable(cnrID cid.ID, operations []eacl.Operation, gateKey ecdsa.PublicKey) eacl.Table {
 	tab := eacl.Table{}
 	tab.SetCID(cnrID)
 	var records []*eacl.Record
 	//allow
-	for op := eacl.OperationGet; op <= eacl.OperationRangeHash; op++ {
+	for _, op := range operations {
 		record := eacl.NewRecord()
 		record.SetOperation(op)
 		record.SetAction(eacl.ActionAllow)
 		equal := eacl.MatchStringEqual
 		equal.DecodeString(cnrID.String())
 		record.AddObjectContainerIDFilter(equal, cnrID)
-		eacl.AddFormedTarget(record, eacl.RoleUnknown, gA.PrivateKey().PrivateKey.PublicKey)
+		eacl.AddFormedTarget(record, eacl.RoleUnknown, gateKey)
 		records = append(records, record)
 	}
 	//deny
@@ -74,9 +117,5 @@ func ObjectBearerToken(cnrID cid.ID, p payload.Parameters, issuerKey keys.Public
 	for _, r := range records {
 		tab.AddRecord(r)
 	}
-	bearerToken.SetEACLTable(tab)
-	var issuer user.ID
-	issuer = user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(issuerKey))
-	bearerToken.SetIssuer(issuer)
-	return bearerToken, nil
+	return tab
 }
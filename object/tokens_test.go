@@ -0,0 +1,46 @@
+package object
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+)
+
+func TestRequiredOperationsCoverTheActions(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnrID := cid.ID(sha256.Sum256([]byte("required operations")))
+	//the requests each action sends to the network
+	cases := []struct {
+		name      string
+		p         ObjectParameter
+		performed []eacl.Operation
+	}{
+		{"create", ObjectParameter{ActionOperation: eacl.OperationPut}, []eacl.Operation{eacl.OperationPut}},
+		{"deduplicated create", ObjectParameter{ActionOperation: eacl.OperationPut, Deduplicate: true}, []eacl.Operation{eacl.OperationSearch, eacl.OperationHead, eacl.OperationPut}},
+		{"read", ObjectParameter{ActionOperation: eacl.OperationGet, Deduplicate: true}, []eacl.Operation{eacl.OperationGet}},
+		{"head", ObjectParameter{ActionOperation: eacl.OperationHead}, []eacl.Operation{eacl.OperationHead}},
+		{"delete", ObjectParameter{ActionOperation: eacl.OperationDelete}, []eacl.Operation{eacl.OperationSearch, eacl.OperationHead, eacl.OperationGet, eacl.OperationDelete}},
+		{"list", ObjectParameter{ActionOperation: eacl.OperationSearch}, []eacl.Operation{eacl.OperationSearch, eacl.OperationHead}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var tok bearer.Token
+			tok.SetEACLTable(operationsTable(cnrID, RequiredOperations(c.p), key.PrivateKey.PublicKey))
+			scope := tokens.BearerScope(&tok)
+			if !scope.Covers(tokens.Scope{Container: cnrID, Operations: c.performed}) {
+				t.Errorf("a token for %v does not allow %v", scope.Operations, c.performed)
+			}
+		})
+	}
+	if ops := RequiredOperations(ObjectParameter{ActionOperation: eacl.OperationPut}); len(ops) != 1 {
+		t.Errorf("an upload without deduplication should only need PUT, got %v", ops)
+	}
+}
@@ -0,0 +1,331 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/notification"
+	"github.com/configwizard/sdk/readwriter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/configwizard/sdk/utils"
+	"github.com/configwizard/sdk/waitgroup"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TransferOptions configure UploadDirectory and DownloadContainer.
+type TransferOptions struct {
+	//Include and Exclude are globs matched against the slash separated relative path. A pattern without a slash
+	//is also matched against the file name alone, so "*.jpg" matches at any depth.
+	Include     []string
+	Exclude     []string
+	Concurrency int //how many transfers run at once, defaults to 4
+	//Progress, if set, reports the combined progress of every file under ProgressName.
+	Progress     *notification.ProgressHandlerManager
+	ProgressName string
+	Logger       *log.Logger
+}
+
+const defaultTransferConcurrency = 4
+
+func (t TransferOptions) concurrency() int {
+	if t.Concurrency < 1 {
+		return defaultTransferConcurrency
+	}
+	return t.Concurrency
+}
+
+func matchGlob(pattern, relative string) bool {
+	if ok, _ := path.Match(pattern, relative); ok {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(relative))
+		return ok
+	}
+	return false
+}
+
+// selected reports whether a relative path passes the include and exclude globs.
+func (t TransferOptions) selected(relative string) bool {
+	for _, pattern := range t.Exclude {
+		if matchGlob(pattern, relative) {
+			return false
+		}
+	}
+	if len(t.Include) == 0 {
+		return true
+	}
+	for _, pattern := range t.Include {
+		if matchGlob(pattern, relative) {
+			return true
+		}
+	}
+	return false
+}
+
+// startProgress registers one progress handler for the whole transfer and returns what to write counted bytes to.
+func (t TransferOptions) startProgress(wg *waitgroup.WG, ctx context.Context, total int64) io.Writer {
+	if t.Progress == nil {
+		return io.Discard
+	}
+	logger := t.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	handler := t.Progress.AddProgressHandler(wg, ctx, io.Discard, t.ProgressName, logger)
+	t.Progress.StartProgressHandler(wg, ctx, t.ProgressName, total)
+	return handler
+}
+
+// transfer runs each job with at most concurrency running at once and returns the first error. No more jobs are started
+// once one has failed, those already running are left to finish.
+func transfer(ctx context.Context, concurrency int, jobs []func() error) error {
+	var (
+		mu       sync.Mutex
+		firstErr error
+		workers  sync.WaitGroup
+	)
+	scheduling, stop := context.WithCancel(ctx)
+	defer stop()
+	sem := make(chan struct{}, concurrency)
+schedule:
+	for _, job := range jobs {
+		select {
+		case <-scheduling.Done():
+			break schedule
+		case sem <- struct{}{}:
+		}
+		if scheduling.Err() != nil {
+			//a job failed while this one waited for a slot
+			break schedule
+		}
+		workers.Add(1)
+		go func(job func() error) {
+			defer func() {
+				<-sem
+				workers.Done()
+			}()
+			if err := job(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				stop()
+			}
+		}(job)
+	}
+	workers.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// progressReader counts what is read from a file towards the progress of a transfer. It can seek, which deduplication
+// needs to hash the file before uploading it, and bytes read again after seeking back are only counted once.
+type progressReader struct {
+	io.ReadSeeker
+	counter  io.Writer
+	position int64
+	counted  int64 //the furthest position read so far
+}
+
+// NewProgressReader counts what is read from r towards the progress written to counter, see progressReader.
+func NewProgressReader(r io.ReadSeeker, counter io.Writer) io.ReadSeeker {
+	return &progressReader{ReadSeeker: r, counter: counter}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(p)
+	r.position += int64(n)
+	if r.position > r.counted {
+		fresh := r.position - r.counted
+		r.counter.Write(p[int64(n)-fresh : n])
+		r.counted = r.position
+	}
+	return n, err
+}
+
+// Seek moves the position. Seeking past what has been read happens when a resumed upload skips what an earlier attempt
+// stored, those bytes are done so they are counted too.
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	position, err := r.ReadSeeker.Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+	r.position = position
+	if r.position > r.counted {
+		skipped := make([]byte, 32*1024)
+		for r.counted < r.position {
+			n := r.position - r.counted
+			if n > int64(len(skipped)) {
+				n = int64(len(skipped))
+			}
+			r.counter.Write(skipped[:n])
+			r.counted += n
+		}
+	}
+	return position, nil
+}
+
+// downloadPaths gives every object a relative path of its own to download to. Objects with the same path, or with a path
+// that is also a directory of another object, would write over each other, so later ones get a numbered suffix.
+func downloadPaths(objects []Object) []string {
+	directories := map[string]bool{}
+	for _, obj := range objects {
+		for dir := path.Dir(objectPath(obj)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			directories[dir] = true
+		}
+	}
+	taken := map[string]bool{}
+	paths := make([]string, len(objects))
+	for i, obj := range objects {
+		relative := objectPath(obj)
+		candidate := relative
+		for n := 1; taken[candidate] || directories[candidate]; n++ {
+			extension := path.Ext(relative)
+			candidate = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(relative, extension), n, extension)
+		}
+		taken[candidate] = true
+		paths[i] = candidate
+	}
+	return paths
+}
+
+// localFile is a file UploadDirectory found to upload.
+type localFile struct {
+	path     string
+	relative string
+	size     int64
+}
+
+// fileUploadKey gives every file of a resumable directory upload progress of its own, files sharing one key would
+// overwrite each other's progress.
+func fileUploadKey(key, relative string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/" + relative
+}
+
+// directoryJobs makes a job for each file that hands its parameters to upload.
+func directoryJobs(files []localFile, p ObjectParameter, counter io.Writer, upload func(ObjectParameter) error) []func() error {
+	var jobs []func() error
+	for _, f := range files {
+		f := f
+		jobs = append(jobs, func() error {
+			file, err := os.Open(f.path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			var pathAttr object.Attribute
+			pathAttr.SetKey(object.AttributeFilePath)
+			pathAttr.SetValue(f.relative)
+			fileParams := p
+			fileParams.Id = ""
+			fileParams.UploadKey = fileUploadKey(p.UploadKey, f.relative)
+			fileParams.Description = f.relative
+			fileParams.Attrs = append(append([]object.Attribute{}, p.Attrs...), pathAttr)
+			fileParams.ReadWriter = &readwriter.DualStream{Reader: &progressReader{ReadSeeker: file, counter: counter}}
+			return upload(fileParams)
+		})
+	}
+	return jobs
+}
+
+// UploadDirectory uploads every selected file under localPath into the container of p, keeping the path relative to
+// localPath in the FilePath attribute. p supplies the container, pool, account and emitter, its Attrs are added to every file.
+// If p has an UploadKey each file is resumable under that key followed by its relative path.
+func (o *ObjectCaller) UploadDirectory(wg *waitgroup.WG, ctx context.Context, localPath string, p ObjectParameter, opts TransferOptions, actionChan chan notification.NewNotification, token tokens.Token) error {
+	var files []localFile
+	var total int64
+	if err := filepath.WalkDir(localPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(localPath, filePath)
+		if err != nil {
+			return err
+		}
+		relative = NormalisePath(filepath.ToSlash(relative))
+		if !opts.selected(relative) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, localFile{path: filePath, relative: relative, size: info.Size()})
+		total += info.Size()
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no files to upload in " + localPath)
+	}
+	counter := opts.startProgress(wg, ctx, total)
+	jobs := directoryJobs(files, p, counter, func(fileParams ObjectParameter) error {
+		return o.Create(wg, ctx, fileParams, actionChan, token)
+	})
+	return transfer(ctx, opts.concurrency(), jobs)
+}
+
+// DownloadContainer downloads every selected object in the container of p into localPath, recreating directories from
+// the FilePath attribute. Objects without a FilePath are saved under their file name, or their ID if they have none.
+func (o *ObjectCaller) DownloadContainer(wg *waitgroup.WG, ctx context.Context, localPath string, p ObjectParameter, opts TransferOptions, actionChan chan notification.NewNotification, token tokens.Token) error {
+	var objects []Object
+	var total int64
+	if _, err := search(ctx, p, SearchQuery{}, token, func(localObject Object) error {
+		if opts.selected(objectPath(localObject)) {
+			objects = append(objects, localObject)
+			total += int64(localObject.Size)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return errors.New(utils.ErrorNotFound)
+	}
+	counter := opts.startProgress(wg, ctx, total)
+
+	var jobs []func() error
+	for i, relative := range downloadPaths(objects) {
+		obj, relative := objects[i], relative
+		jobs = append(jobs, func() error {
+			//NormalisePath has already removed any .. so this cannot escape localPath
+			destination := filepath.Join(localPath, filepath.FromSlash(relative))
+			if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+				return err
+			}
+			file, err := os.Create(destination)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			objectParams := p
+			objectParams.Id = obj.Id
+			objectParams.Description = relative
+			objectParams.ReadWriter = &readwriter.DualStream{Writer: io.MultiWriter(file, counter)}
+			if err := o.Read(wg, ctx, objectParams, actionChan, token); err != nil {
+				return fmt.Errorf("%s: %w", relative, err)
+			}
+			return nil
+		})
+	}
+	return transfer(ctx, opts.concurrency(), jobs)
+}
@@ -0,0 +1,202 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/configwizard/sdk/readwriter"
+)
+
+func TestTransferOptionsSelected(t *testing.T) {
+	opts := TransferOptions{
+		Include: []string{"*.jpg", "docs/*"},
+		Exclude: []string{"private/*", "*.tmp.jpg"},
+	}
+	cases := map[string]bool{
+		"a.jpg":             true,
+		"photos/2024/b.jpg": true,
+		"docs/cv.pdf":       true,
+		"docs/old/cv.pdf":   false,
+		"private/c.jpg":     false,
+		"photos/d.tmp.jpg":  false,
+		"notes.txt":         false,
+	}
+	for relative, want := range cases {
+		if got := opts.selected(relative); got != want {
+			t.Errorf("selected(%q) = %v, want %v", relative, got, want)
+		}
+	}
+	if !(TransferOptions{}).selected("anything/at/all") {
+		t.Error("no globs should select everything")
+	}
+}
+
+func TestTransferConcurrencyLimit(t *testing.T) {
+	var running, peak int32
+	var jobs []func() error
+	for i := 0; i < 20; i++ {
+		jobs = append(jobs, func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	if err := transfer(context.Background(), 3, jobs); err != nil {
+		t.Fatal(err)
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 transfers at once, saw %d", peak)
+	}
+}
+
+func TestTransferStopsAfterFailure(t *testing.T) {
+	var started int32
+	failure := errors.New("upload failed")
+	jobs := []func() error{func() error {
+		atomic.AddInt32(&started, 1)
+		return failure
+	}}
+	for i := 0; i < 10; i++ {
+		jobs = append(jobs, func() error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		})
+	}
+	if err := transfer(context.Background(), 1, jobs); err != failure {
+		t.Fatalf("expected the failure, got %v", err)
+	}
+	if started != 1 {
+		t.Errorf("no job should start after one failed, %d started", started)
+	}
+}
+
+func TestDownloadPaths(t *testing.T) {
+	objects := []Object{
+		{Id: "1", FilePath: "photos/a.jpg"},
+		{Id: "2", FilePath: "photos/a.jpg"},
+		{Id: "3", Name: "photos"},
+		{Id: "4", FilePath: "notes"},
+		{Id: "5", FilePath: "notes"},
+	}
+	want := []string{"photos/a.jpg", "photos/a (1).jpg", "photos (1)", "notes", "notes (1)"}
+	got := downloadPaths(objects)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("object %s downloads to %q, want %q", objects[i].Id, got[i], want[i])
+		}
+	}
+}
+
+func TestProgressReaderCountsOnce(t *testing.T) {
+	var counted bytes.Buffer
+	r := &progressReader{ReadSeeker: strings.NewReader("payload"), counter: &counted}
+	//deduplication hashes the file, then the upload reads it again
+	if _, _, err := PayloadChecksum(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if counted.String() != "payload" {
+		t.Errorf("counted %q, want each byte once", counted.String())
+	}
+}
+
+func TestProgressReaderCountsSkippedBytes(t *testing.T) {
+	var counted bytes.Buffer
+	r := &progressReader{ReadSeeker: strings.NewReader("payload"), counter: &counted}
+	//a resumed upload skips what an earlier attempt stored
+	if _, err := r.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if counted.Len() != len("payload") {
+		t.Errorf("counted %d bytes, want %d", counted.Len(), len("payload"))
+	}
+}
+
+func TestDirectoryUploadResumes(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string]string{"a.txt": "first file", "sub/b.txt": "second file"}
+	var files []localFile
+	var total int64
+	for relative, content := range contents {
+		filePath := filepath.Join(dir, filepath.FromSlash(relative))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, localFile{path: filePath, relative: relative, size: int64(len(content))})
+		total += int64(len(content))
+	}
+
+	//stored is what each upload key has stored so far, the way a ResumableWriter persists it
+	var mu sync.Mutex
+	stored := map[string][]byte{}
+	fail := true
+	upload := func(p ObjectParameter) error {
+		mu.Lock()
+		done := stored[p.UploadKey]
+		mu.Unlock()
+		//as Create does, the reader skips ahead by seeking
+		reader := p.ReadWriter.(*readwriter.DualStream).Reader
+		if err := skipStored(reader, uint64(len(done))); err != nil {
+			return err
+		}
+		rest, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if fail && p.Description == "sub/b.txt" {
+			//the node went away part way through
+			stored[p.UploadKey] = append(done, rest[:4]...)
+			return errors.New("node unavailable")
+		}
+		stored[p.UploadKey] = append(done, rest...)
+		return nil
+	}
+	p := ObjectParameter{UploadKey: "backup"}
+	var firstAttempt bytes.Buffer
+	if err := transfer(context.Background(), 2, directoryJobs(files, p, &firstAttempt, upload)); err == nil {
+		t.Fatal("the first attempt should fail")
+	}
+	fail = false
+	var counted bytes.Buffer
+	if err := transfer(context.Background(), 2, directoryJobs(files, p, &counted, upload)); err != nil {
+		t.Fatal(err)
+	}
+	for relative, content := range contents {
+		if got := string(stored["backup/"+relative]); got != content {
+			t.Errorf("%s stored %q, want %q", relative, got, content)
+		}
+	}
+	if len(stored) != len(contents) {
+		t.Errorf("%d upload keys used, want one per file", len(stored))
+	}
+	if int64(counted.Len()) != total {
+		t.Errorf("resumed upload counted %d bytes, want %d", counted.Len(), total)
+	}
+}
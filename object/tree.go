@@ -0,0 +1,291 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/emitter"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// TreeNode is a directory or a file in the virtual filesystem built from the FilePath attribute of objects.
+// Directories only exist because objects have paths under them, so Object is nil for a directory.
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	Object   *Object     `json:"object,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+func (t *TreeNode) IsDir() bool {
+	return t.Object == nil
+}
+
+// NormalisePath cleans a file path into the form stored in the FilePath attribute: forward slashes, no leading slash
+// and no trailing slash. The root is the empty string.
+func NormalisePath(p string) string {
+	p = path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))
+	return strings.TrimPrefix(p, "/")
+}
+
+// objectPath is where an object sits in the tree. Objects uploaded without a FilePath sit at the root under their name.
+func objectPath(o Object) string {
+	if o.FilePath != "" {
+		return o.FilePath
+	}
+	if o.Name != "" {
+		return NormalisePath(o.Name)
+	}
+	return o.Id
+}
+
+// BuildTree arranges objects into a tree rooted at root. Objects outside root are left out.
+// Children are sorted with directories first, then by name.
+func BuildTree(root string, objects []Object) *TreeNode {
+	root = NormalisePath(root)
+	tree := &TreeNode{Name: path.Base("/" + root), Path: root}
+	for i := range objects {
+		p := objectPath(objects[i])
+		relative := p
+		if root != "" {
+			if !strings.HasPrefix(p, root+"/") {
+				continue
+			}
+			relative = strings.TrimPrefix(p, root+"/")
+		}
+		node := tree
+		parts := strings.Split(relative, "/")
+		for j, part := range parts {
+			if j == len(parts)-1 {
+				obj := objects[i]
+				node.Children = append(node.Children, &TreeNode{Name: part, Path: p, Object: &obj})
+				break
+			}
+			var next *TreeNode
+			for _, c := range node.Children {
+				if c.IsDir() && c.Name == part {
+					next = c
+					break
+				}
+			}
+			if next == nil {
+				next = &TreeNode{Name: part, Path: path.Join(node.Path, part)}
+				node.Children = append(node.Children, next)
+			}
+			node = next
+		}
+	}
+	sortTree(tree)
+	return tree
+}
+
+func sortTree(t *TreeNode) {
+	sort.SliceStable(t.Children, func(i, j int) bool {
+		if t.Children[i].IsDir() != t.Children[j].IsDir() {
+			return t.Children[i].IsDir()
+		}
+		return t.Children[i].Name < t.Children[j].Name
+	})
+	for _, c := range t.Children {
+		sortTree(c)
+	}
+}
+
+// withFilePath normalises a FilePath attribute if there is one, and names the file after it when no FileName was given.
+func withFilePath(attrs []object.Attribute) []object.Attribute {
+	filePath := ""
+	hasName := false
+	for i := range attrs {
+		switch attrs[i].Key() {
+		case object.AttributeFilePath:
+			filePath = NormalisePath(attrs[i].Value())
+			attrs[i].SetValue(filePath)
+		case object.AttributeFileName:
+			hasName = true
+		}
+	}
+	if filePath != "" && !hasName {
+		var nameAttr object.Attribute
+		nameAttr.SetKey(object.AttributeFileName)
+		nameAttr.SetValue(path.Base(filePath))
+		attrs = append(attrs, nameAttr)
+	}
+	return attrs
+}
+
+// ListDirectory returns the tree of everything under dir in the container and emits it as an ObjectTreeUpdate.
+// The empty dir is the root of the container.
+func (o *ObjectCaller) ListDirectory(ctx context.Context, p ObjectParameter, dir string, token tokens.Token) (*TreeNode, error) {
+	dir = NormalisePath(dir)
+	var query SearchQuery
+	if dir != "" {
+		query.Filters = append(query.Filters, SearchFilter{Key: object.AttributeFilePath, Value: dir + "/", Match: object.MatchCommonPrefix})
+	}
+	var objects []Object
+	if _, err := search(ctx, p, query, token, func(localObject Object) error {
+		objects = append(objects, localObject)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	tree := BuildTree(dir, objects)
+	if p.ObjectEmitter != nil {
+		if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectTreeUpdate, tree); err != nil {
+			fmt.Println("could not emit tree", err)
+		}
+	}
+	return tree, nil
+}
+
+// moveSource is how Move reads p: the payload is moved as it is stored, so an encrypted object stays encrypted under
+// the attributes that are moved with it.
+func moveSource(p ObjectParameter) ObjectParameter {
+	p.Verify = false
+	p.DecryptionKey = nil
+	return p
+}
+
+// moveDestination is how Move writes the object that was p, with attrs, at newPath. The timestamp is set again by the
+// writer and the path and name are replaced.
+func moveDestination(p ObjectParameter, attrs []object.Attribute, newPath string) ObjectParameter {
+	var kept []object.Attribute
+	for _, a := range attrs {
+		switch a.Key() {
+		case object.AttributeTimestamp, object.AttributeFilePath, object.AttributeFileName:
+			continue
+		}
+		kept = append(kept, a)
+	}
+	var pathAttr, nameAttr object.Attribute
+	pathAttr.SetKey(object.AttributeFilePath)
+	pathAttr.SetValue(newPath)
+	nameAttr.SetKey(object.AttributeFileName)
+	nameAttr.SetValue(path.Base(newPath))
+
+	moved := p
+	moved.Id = ""
+	moved.Attrs = append(kept, pathAttr, nameAttr)
+	//the payload is already encrypted if it needs to be
+	moved.Encrypt = false
+	return moved
+}
+
+// Move gives an object a new path. Objects are immutable, so this re-uploads the payload with the new FilePath and
+// FileName and then deletes the original. An encrypted object is shared again with everyone the original was shared
+// with. The token needs to allow get, put, search, head and delete, so it has to be a bearer token: an object session
+// covers a single operation.
+func (o *ObjectCaller) Move(ctx context.Context, p ObjectParameter, newPath string, token tokens.Token) (Object, error) {
+	newPath = NormalisePath(newPath)
+	if newPath == "" {
+		return Object{}, errors.New("cannot move an object to the root itself")
+	}
+	var objID oid.ID
+	if err := objID.DecodeString(p.ID()); err != nil {
+		fmt.Println("wrong object Id", err)
+		return Object{}, err
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(p.ParentID()); err != nil {
+		fmt.Println("wrong container Id", err)
+		return Object{}, err
+	}
+	gA, err := p.ForUser()
+	if err != nil {
+		return Object{}, err
+	}
+	//checked before anything is uploaded, a copy left behind would be a duplicate
+	var prmDelete client.PrmObjectDelete
+	if token != nil {
+		if tok, ok := token.(*tokens.BearerToken); !ok {
+			if tok, ok := token.(*tokens.PrivateBearerToken); !ok {
+				return Object{}, errors.New("no bearer token provided")
+			} else {
+				prmDelete.WithBearerToken(*tok.BearerToken)
+			}
+		} else {
+			prmDelete.WithBearerToken(*tok.BearerToken)
+		}
+	}
+	if err := o.checkLocks(ctx, p, token); err != nil {
+		return Object{}, err
+	}
+	hdr, objectReader, err := InitReader(ctx, moveSource(p), token)
+	if err != nil {
+		return Object{}, err
+	}
+	defer objectReader.Close()
+
+	moved := moveDestination(p, hdr.Attributes(), newPath)
+	writer, err := InitWriter(ctx, &moved, token)
+	if err != nil {
+		return Object{}, err
+	}
+	if _, err := io.Copy(writer, objectReader); err != nil {
+		return Object{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return Object{}, err
+	}
+	payloadWriter, ok := writer.(idWriteCloser)
+	if !ok {
+		return Object{}, errors.New("could not retrieve the new object id")
+	}
+	newID := payloadWriter.ID()
+
+	var keyObjects []Object
+	if IsEncrypted(hdr) {
+		//key objects name the object they share, the copy needs its own
+		if keyObjects, err = reshareEncryptedObject(ctx, p, newID.String(), token); err != nil {
+			return Object{ParentID: p.ParentID(), Id: newID.String(), FilePath: newPath}, fmt.Errorf("share the moved object: %w", err)
+		}
+	}
+	gateSigner := user.NewAutoIDSignerRFC6979(gA.PrivateKey().PrivateKey)
+	if _, err := p.Pool().ObjectDelete(ctx, cnrID, objID, gateSigner, prmDelete); err != nil {
+		//the copy exists, so report the new id along with the error
+		return Object{ParentID: p.ParentID(), Id: newID.String(), FilePath: newPath}, asLockedError(p.ID(), err)
+	}
+	for _, keyObject := range keyObjects {
+		var keyID oid.ID
+		if err := keyID.DecodeString(keyObject.Id); err != nil {
+			fmt.Println("wrong key object Id", err)
+			continue
+		}
+		if _, err := p.Pool().ObjectDelete(ctx, cnrID, keyID, gateSigner, prmDelete); err != nil {
+			fmt.Println("could not delete the key object of the original ", keyObject.Id, err)
+		}
+	}
+
+	movedObject := Object{
+		ParentID:   p.ParentID(),
+		Id:         newID.String(),
+		Name:       path.Base(newPath),
+		FilePath:   newPath,
+		Size:       hdr.PayloadSize(),
+		Attributes: make(map[string]string),
+	}
+	for _, a := range moved.Attrs {
+		switch a.Key() {
+		case object.AttributeContentType:
+			movedObject.ContentType = a.Value()
+		}
+		movedObject.Attributes[a.Key()] = a.Value()
+	}
+	if p.ObjectEmitter != nil {
+		if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectRemoveUpdate, Object{ParentID: p.ParentID(), Id: p.ID()}); err != nil {
+			fmt.Println("could not emit update", err)
+		}
+		if err := p.ObjectEmitter.Emit(ctx, emitter.ObjectAddUpdate, movedObject); err != nil {
+			fmt.Println("could not emit update", err)
+		}
+	}
+	return movedObject, nil
+}
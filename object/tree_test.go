@@ -0,0 +1,149 @@
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+func TestNormalisePath(t *testing.T) {
+	cases := map[string]string{
+		"":                   "",
+		"/":                  "",
+		"photos/":            "photos",
+		"/photos/2024/a.jpg": "photos/2024/a.jpg",
+		"photos//./b.jpg":    "photos/b.jpg",
+		"photos\\c.jpg":      "photos/c.jpg",
+		"../escape.txt":      "escape.txt",
+	}
+	for in, want := range cases {
+		if got := NormalisePath(in); got != want {
+			t.Errorf("NormalisePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	objects := []Object{
+		{Id: "1", FilePath: "photos/2024/b.jpg"},
+		{Id: "2", FilePath: "photos/2024/a.jpg"},
+		{Id: "3", FilePath: "photos/cover.png"},
+		{Id: "4", Name: "readme.txt"},
+		{Id: "5", FilePath: "docs/cv.pdf"},
+	}
+	tree := BuildTree("", objects)
+	if len(tree.Children) != 3 {
+		t.Fatalf("expected 3 children at the root, got %d", len(tree.Children))
+	}
+	if tree.Children[0].Name != "docs" || tree.Children[1].Name != "photos" || tree.Children[2].Name != "readme.txt" {
+		t.Errorf("unexpected root order %s, %s, %s", tree.Children[0].Name, tree.Children[1].Name, tree.Children[2].Name)
+	}
+	photos := tree.Children[1]
+	if !photos.IsDir() || photos.Path != "photos" {
+		t.Fatalf("expected photos directory, got %+v", photos)
+	}
+	if len(photos.Children) != 2 || photos.Children[0].Name != "2024" || photos.Children[1].Name != "cover.png" {
+		t.Fatalf("directories should sort before files in %s", photos.Path)
+	}
+	year := photos.Children[0]
+	if year.Path != "photos/2024" || len(year.Children) != 2 {
+		t.Fatalf("unexpected 2024 directory %+v", year)
+	}
+	if year.Children[0].Object.Id != "2" || year.Children[0].Path != "photos/2024/a.jpg" {
+		t.Errorf("expected a.jpg first, got %+v", year.Children[0])
+	}
+
+	sub := BuildTree("/photos/", objects)
+	if sub.Path != "photos" || sub.Name != "photos" || len(sub.Children) != 2 {
+		t.Errorf("unexpected subtree %+v", sub)
+	}
+}
+
+func TestMoveKeepsEncryptedPayload(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := ObjectParameter{Id: "object", DecryptionKey: key, Verify: true, Encrypt: true}
+	if source := moveSource(p); source.DecryptionKey != nil || source.Verify {
+		t.Fatal("a move should read the payload as it is stored")
+	}
+
+	attrs := make([]object.Attribute, 5)
+	attrs[0].SetKey(object.AttributeFilePath)
+	attrs[0].SetValue("photos/a.jpg")
+	attrs[1].SetKey(object.AttributeFileName)
+	attrs[1].SetValue("a.jpg")
+	attrs[2].SetKey(object.AttributeTimestamp)
+	attrs[2].SetValue("1700000000")
+	attrs[3].SetKey(AttributeEncryption)
+	attrs[3].SetValue("1")
+	attrs[4].SetKey(AttributeEncryptionNonce)
+	attrs[4].SetValue("nonce")
+	moved := moveDestination(p, attrs, "archive/a.jpg")
+	if moved.Id != "" || moved.Encrypt {
+		t.Fatal("the moved object is a new object and its payload is already encrypted")
+	}
+	got := map[string]string{}
+	for _, a := range moved.Attrs {
+		got[a.Key()] = a.Value()
+	}
+	want := map[string]string{
+		object.AttributeFilePath: "archive/a.jpg",
+		object.AttributeFileName: "a.jpg",
+		AttributeEncryption:      "1",
+		AttributeEncryptionNonce: "nonce",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("moved attributes %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("moved %s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestMovedKeyObjectSharesTheCopy(t *testing.T) {
+	recipient, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+	wrapped, err := WrapKey(dataKey, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyObject := Object{Id: "key", Attributes: map[string]string{}}
+	for _, a := range keyObjectAttributes("original", publicKeyHex(&recipient.PublicKey), wrapped) {
+		keyObject.Attributes[a.Key()] = a.Value()
+	}
+	keyObject.Attributes[object.AttributeTimestamp] = "1700000000"
+
+	attrs, err := movedKeyObject(keyObject, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved := map[string]string{}
+	for _, a := range attrs {
+		moved[a.Key()] = a.Value()
+	}
+	if moved[AttributeEncryptedObject] != "copy" {
+		t.Errorf("the key object should share the copy, it shares %q", moved[AttributeEncryptedObject])
+	}
+	if _, ok := moved[object.AttributeTimestamp]; ok {
+		t.Error("the writer sets the timestamp of the new key object")
+	}
+	unwrapped, err := UnwrapKey(moved[AttributeEncryptionKeyPrefix+publicKeyHex(&recipient.PublicKey)], recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unwrapped) != string(dataKey) {
+		t.Error("the recipient should still unwrap the data key")
+	}
+
+	if _, err := movedKeyObject(Object{Id: "plain", Attributes: map[string]string{}}, "copy"); err == nil {
+		t.Error("an object without a wrapped key cannot be moved as a key object")
+	}
+}
@@ -0,0 +1,102 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/configwizard/sdk/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/tzhash/tz"
+	"hash"
+	"io"
+)
+
+const (
+	ChecksumSHA256      = "sha256"
+	ChecksumHomomorphic = "tillich-zemor"
+)
+
+// ChecksumMismatchError is returned when a payload read from the network does not hash to what its header says.
+type ChecksumMismatchError struct {
+	ObjectID string
+	Kind     string //ChecksumSHA256 or ChecksumHomomorphic
+	Expected string
+	Actual   string
+}
+
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("object %s failed %s verification: expected %s, got %s", e.ObjectID, e.Kind, e.Expected, e.Actual)
+}
+
+// verifyingReader hashes a payload as it is read and checks it against the header once the payload ends.
+// Instead of io.EOF the final read returns a ChecksumMismatchError if the payload was not what the header promised.
+type verifyingReader struct {
+	io.Reader
+	objectID    string
+	sha         hash.Hash
+	homomorphic hash.Hash //nil when the header carries no homomorphic hash
+	expectedSHA []byte
+	expectedTZ  []byte
+}
+
+func newVerifyingReader(hdr object.Object, r io.Reader) *verifyingReader {
+	v := &verifyingReader{
+		Reader: r,
+		sha:    sha256.New(),
+	}
+	if id, ok := hdr.ID(); ok {
+		v.objectID = id.String()
+	}
+	if cs, ok := hdr.PayloadChecksum(); ok {
+		v.expectedSHA = cs.Value()
+	}
+	//the homomorphic hash is only present when the network has it enabled
+	if cs, ok := hdr.PayloadHomomorphicHash(); ok {
+		v.expectedTZ = cs.Value()
+		v.homomorphic = tz.New()
+	}
+	return v
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.Reader.Read(p)
+	if n > 0 {
+		v.sha.Write(p[:n])
+		if v.homomorphic != nil {
+			v.homomorphic.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		if verifyErr := v.verify(); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+func (v *verifyingReader) verify() error {
+	if actual := v.sha.Sum(nil); v.expectedSHA != nil && !bytes.Equal(actual, v.expectedSHA) {
+		return ChecksumMismatchError{ObjectID: v.objectID, Kind: ChecksumSHA256, Expected: hex.EncodeToString(v.expectedSHA), Actual: hex.EncodeToString(actual)}
+	}
+	if v.homomorphic != nil {
+		if actual := v.homomorphic.Sum(nil); !bytes.Equal(actual, v.expectedTZ) {
+			return ChecksumMismatchError{ObjectID: v.objectID, Kind: ChecksumHomomorphic, Expected: hex.EncodeToString(v.expectedTZ), Actual: hex.EncodeToString(actual)}
+		}
+	}
+	return nil
+}
+
+// VerifyObject downloads an object already stored on the network and checks its payload against the checksums in its header.
+// A ChecksumMismatchError means the stored payload is not what was uploaded.
+func VerifyObject(ctx context.Context, params ObjectParameter, token tokens.Token) error {
+	params.Verify = true
+	_, objectReader, err := InitReader(ctx, params, token)
+	if err != nil {
+		return err
+	}
+	defer objectReader.Close()
+	_, err = io.Copy(io.Discard, objectReader)
+	return err
+}
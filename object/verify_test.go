@@ -0,0 +1,48 @@
+package object
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/checksum"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/tzhash/tz"
+)
+
+func headerFor(payload []byte) object.Object {
+	var hdr object.Object
+	var cs checksum.Checksum
+	cs.SetSHA256(sha256.Sum256(payload))
+	hdr.SetPayloadChecksum(cs)
+	var homo checksum.Checksum
+	homo.SetTillichZemor(tz.Sum(payload))
+	hdr.SetPayloadHomomorphicHash(homo)
+	return hdr
+}
+
+func TestVerifyingReader(t *testing.T) {
+	payload := bytes.Repeat([]byte("neofs"), 1000)
+	hdr := headerFor(payload)
+
+	read, err := io.ReadAll(newVerifyingReader(hdr, bytes.NewReader(payload)))
+	if err != nil {
+		t.Fatalf("expected a matching payload to verify, got %s", err)
+	}
+	if !bytes.Equal(read, payload) {
+		t.Fatal("payload was altered while verifying")
+	}
+
+	corrupt := append([]byte{}, payload...)
+	corrupt[10] ^= 0xff
+	_, err = io.ReadAll(newVerifyingReader(hdr, bytes.NewReader(corrupt)))
+	var mismatch ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected a ChecksumMismatchError, got %v", err)
+	}
+	if mismatch.Kind != ChecksumSHA256 {
+		t.Errorf("expected the sha256 check to fail first, got %s", mismatch.Kind)
+	}
+}
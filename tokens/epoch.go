@@ -0,0 +1,124 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSafetyMargin is how many epochs before a token expires the token managers stop handing it out, so that it does
+// not expire part way through an action.
+const DefaultSafetyMargin uint64 = 1
+
+// DefaultEpochTTL is how long an EpochCache trusts the epoch it fetched. Epochs last around an hour on mainnet.
+const DefaultEpochTTL = 30 * time.Second
+
+// EpochCache remembers the network's current epoch, fetching it again once it is older than TTL.
+type EpochCache struct {
+	Fetch   func(ctx context.Context) (uint64, error) //usually NetworkInfo's CurrentEpoch
+	TTL     time.Duration
+	epoch   uint64
+	fetched time.Time
+	now     func() time.Time //for tests
+	mutex   sync.Mutex
+}
+
+func NewEpochCache(fetch func(ctx context.Context) (uint64, error), ttl time.Duration) *EpochCache {
+	return &EpochCache{Fetch: fetch, TTL: ttl, now: time.Now}
+}
+
+// Current returns the cached epoch, or fetches it if the cache is empty or stale.
+func (e *EpochCache) Current(ctx context.Context) (uint64, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.now == nil {
+		e.now = time.Now
+	}
+	if !e.fetched.IsZero() && e.now().Sub(e.fetched) < e.TTL {
+		return e.epoch, nil
+	}
+	if e.Fetch == nil {
+		return 0, errors.New("no way to fetch the current epoch")
+	}
+	epoch, err := e.Fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	e.epoch, e.fetched = epoch, e.now()
+	return epoch, nil
+}
+
+// Invalidate forgets the cached epoch so the next Current fetches it, e.g. when the network reports a new epoch.
+func (e *EpochCache) Invalidate() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.fetched = time.Time{}
+}
+
+// currentEpoch is the epoch from epochs, if there are any and it can be fetched with ctx, otherwise fallback.
+// The managers call it before taking their lock, as fetching the epoch may take a while.
+func currentEpoch(ctx context.Context, epochs *EpochCache, fallback uint64) uint64 {
+	if epochs == nil {
+		return fallback
+	}
+	epoch, err := epochs.Current(ctx)
+	if err != nil {
+		fmt.Println("could not retrieve the current epoch ", err)
+		return fallback
+	}
+	return epoch
+}
+
+// usableToken returns the token kept under key if it can be used at epoch. A token that expires within margin epochs is
+// evicted from tokens and store, one that is not valid yet is left for later.
+func usableToken(store *TokenStore, tokens map[string]Token, key string, epoch, margin uint64) (Token, error) {
+	tok, ok := tokens[key]
+	if !ok {
+		return nil, errors.New(utils.ErrorNoToken)
+	}
+	lifetime := tok.Lifetime()
+	if lifetime.Expired(epoch + margin) {
+		delete(tokens, key)
+		if store != nil {
+			if err := store.Delete(isSession(tok), key); err != nil {
+				fmt.Println("could not delete token ", key, err)
+			}
+		}
+		return nil, errors.New(utils.ErrorNoToken)
+	}
+	if !lifetime.ValidAt(epoch) {
+		return nil, errors.New(utils.ErrorNoToken)
+	}
+	return tok, nil
+}
+
+// narrowestBearer returns the usable bearer token for address with the narrowest scope covering request.
+func narrowestBearer(store *TokenStore, tokens map[string]Token, address string, request Scope, epoch, margin uint64) (Token, error) {
+	prefix := fmt.Sprintf("%s.%s", address, request.Container)
+	var found Token
+	var foundScope Scope
+	for key := range tokens {
+		if key != prefix && !strings.HasPrefix(key, prefix+".") {
+			continue
+		}
+		tok, err := usableToken(store, tokens, key, epoch, margin)
+		if err != nil {
+			continue
+		}
+		scope := tokenScope(tok)
+		if !scope.Covers(request) {
+			continue
+		}
+		if found == nil || scope.narrower(foundScope) {
+			found, foundScope = tok, scope
+		}
+	}
+	if found == nil {
+		return nil, errors.New(utils.ErrorNoToken)
+	}
+	return found, nil
+}
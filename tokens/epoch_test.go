@@ -0,0 +1,111 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/configwizard/sdk/database"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochCache(t *testing.T) {
+	var fetches int
+	epoch := uint64(10)
+	now := time.Now()
+	cache := NewEpochCache(func(ctx context.Context) (uint64, error) {
+		fetches++
+		return epoch, nil
+	}, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	got, err := cache.Current(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 10, got)
+	epoch = 11
+	got, _ = cache.Current(context.Background())
+	require.EqualValues(t, 10, got, "the cached epoch should be used within the TTL")
+	now = now.Add(time.Minute)
+	got, _ = cache.Current(context.Background())
+	require.EqualValues(t, 11, got)
+	cache.Invalidate()
+	_, _ = cache.Current(context.Background())
+	require.Equal(t, 3, fetches)
+}
+
+func TestTokenLifetimes(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("lifetime")))
+	b := testBearer(t, userAccount, cnrID, 20)
+	s := BuildUnsignedContainerSessionToken(2, 3, 20, cnrID, session.VerbContainerPut, *userAccount.PublicKey())
+
+	for _, tok := range []Token{&BearerToken{BearerToken: b}, &PrivateBearerToken{BearerToken: b}} {
+		require.Equal(t, Lifetime{Iat: 1, Nbf: 1, Exp: 20}, tok.Lifetime())
+		require.False(t, tok.InvalidAt(20))
+		require.True(t, tok.InvalidAt(21))
+	}
+	for _, tok := range []Token{&ContainerSessionToken{SessionToken: s}, &PrivateContainerSessionToken{SessionToken: s}} {
+		require.Equal(t, Lifetime{Iat: 2, Nbf: 3, Exp: 20}, tok.Lifetime())
+		require.True(t, tok.InvalidAt(2), "not valid before nbf")
+		require.False(t, tok.InvalidAt(3))
+		require.True(t, tok.InvalidAt(21))
+	}
+}
+
+func TestFindUsesTheNetworkEpoch(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	db := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("epochs")))
+
+	manager := NewWalletConnectTokenManager(gate)
+	require.NoError(t, manager.Persist(NewTokenStore(db, DeriveStoreKey(userAccount)), 1))
+	networkEpoch := uint64(5)
+	manager.SetEpochs(NewEpochCache(func(ctx context.Context) (uint64, error) {
+		return networkEpoch, nil
+	}, 0))
+	manager.SafetyMargin = 2
+	manager.AddBearerToken(userAccount.Address, cnrID.String(), &BearerToken{BearerToken: testBearer(t, userAccount, cnrID, 10)})
+
+	//the epoch passed in is ignored in favour of the network's
+	_, err = manager.FindBearerToken(context.Background(), userAccount.Address, cnrID, 100, eacl.OperationGet)
+	require.NoError(t, err)
+	_, err = manager.FindBearerToken(context.Background(), userAccount.Address, cnrID, 100, eacl.OperationPut)
+	require.Error(t, err, "the token does not allow put")
+
+	networkEpoch = 9
+	_, err = manager.FindBearerToken(context.Background(), userAccount.Address, cnrID, 1, eacl.OperationGet)
+	require.Error(t, err, "a token within the safety margin of expiring should not be found")
+	require.Empty(t, manager.BearerTokens, "and should be evicted")
+	stored, err := db.SelectAll(database.TokenBucket)
+	require.NoError(t, err)
+	require.Len(t, stored, 1, "only the gate key should be left")
+}
+
+func TestFindFetchesTheEpochWithTheCallersContext(t *testing.T) {
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("epoch context")))
+
+	type key struct{}
+	var fetchedWith context.Context
+	manager := NewPrivateKeyTokenManager(gate)
+	manager.SetEpochs(NewEpochCache(func(ctx context.Context) (uint64, error) {
+		fetchedWith = ctx
+		//a manager that fetched while holding its lock would deadlock here
+		manager.AddBearerToken("address", cnrID.String(), &PrivateBearerToken{BearerToken: testBearer(t, gate, cnrID, 10), Wallet: gate})
+		return 5, nil
+	}, 0))
+	ctx := context.WithValue(context.Background(), key{}, "caller")
+	_, err = manager.FindBearerToken(ctx, "address", cnrID, 0, eacl.OperationGet)
+	require.NoError(t, err)
+	require.Equal(t, "caller", fetchedWith.Value(key{}))
+}
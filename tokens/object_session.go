@@ -0,0 +1,182 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/payload"
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-api-go/v2/refs"
+	session2 "github.com/nspcc-dev/neofs-api-go/v2/session"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// objectVerbs maps the eACL operation of an object action to the object session verb that allows it.
+var objectVerbs = map[eacl.Operation]session.ObjectVerb{
+	eacl.OperationGet:       session.VerbObjectGet,
+	eacl.OperationHead:      session.VerbObjectHead,
+	eacl.OperationPut:       session.VerbObjectPut,
+	eacl.OperationDelete:    session.VerbObjectDelete,
+	eacl.OperationSearch:    session.VerbObjectSearch,
+	eacl.OperationRange:     session.VerbObjectRange,
+	eacl.OperationRangeHash: session.VerbObjectRangeHash,
+}
+
+// ObjectVerb is the object session verb for an action doing operation.
+func ObjectVerb(operation eacl.Operation) (session.ObjectVerb, bool) {
+	verb, ok := objectVerbs[operation]
+	return verb, ok
+}
+
+// objectSessionVerb is the verb an object session token was issued for.
+func objectSessionVerb(tok *session.Object) session.ObjectVerb {
+	if tok == nil {
+		return 0
+	}
+	for verb := session.VerbObjectPut; verb <= session.VerbObjectRangeHash; verb++ {
+		if tok.AssertVerb(verb) {
+			return verb
+		}
+	}
+	return 0
+}
+
+func objectSessionLifetime(tok *session.Object) (Lifetime, bool) {
+	if tok == nil {
+		return Lifetime{}, false
+	}
+	var m session2.Token
+	tok.WriteToV2(&m)
+	l := m.GetBody().GetLifetime()
+	return Lifetime{Iat: l.GetIat(), Nbf: l.GetNbf(), Exp: l.GetExp()}, true
+}
+
+// ObjectSessionToken is an object session token signed with WalletConnect.
+type ObjectSessionToken struct {
+	SessionToken *session.Object
+	Signature    payload.Signature
+}
+
+func (s *ObjectSessionToken) SetSignature(sig payload.Signature) {
+	s.Signature = sig
+}
+func (s ObjectSessionToken) GetSignature() payload.Signature {
+	return s.Signature
+}
+func (s ObjectSessionToken) InvalidAt(epoch uint64) bool {
+	return s.SessionToken.InvalidAt(epoch)
+}
+func (s ObjectSessionToken) Lifetime() Lifetime {
+	l, _ := objectSessionLifetime(s.SessionToken)
+	return l
+}
+func (s ObjectSessionToken) SignedData() []byte {
+	return s.SessionToken.SignedData()
+}
+func (s ObjectSessionToken) Sign(issuerAddress string, p payload.Payload) error {
+	if s.SessionToken == nil {
+		return errors.New(utils.ErrorNoToken)
+	}
+	if p.Signature == nil {
+		return errors.New(utils.ErrorNoSignature)
+	}
+	bPubKey, err := hex.DecodeString(p.Signature.HexPublicKey)
+	if err != nil {
+		return err
+	}
+	var pubKey neofsecdsa.PublicKeyWalletConnect
+	if err := pubKey.Decode(bPubKey); err != nil {
+		return err
+	}
+	issuer := user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(pubKey))
+	bSig, err := hex.DecodeString(p.Signature.HexSignature)
+	if err != nil {
+		fmt.Println("error decoding hex signature", err)
+		return err
+	}
+	salt, err := hex.DecodeString(p.Signature.HexSalt)
+	if err != nil {
+		fmt.Println("error decoding hex salt", err)
+		return err
+	}
+	staticSigner := neofscrypto.NewStaticSigner(neofscrypto.ECDSA_WALLETCONNECT, append(bSig, salt...), &pubKey)
+	if err := s.SessionToken.Sign(user.NewSigner(staticSigner, issuer)); err != nil {
+		return err
+	}
+	if !s.SessionToken.VerifySignature() {
+		fmt.Println("verifying signature failed for object session token")
+		return errors.New(utils.ErrorNoSignature)
+	}
+	return nil
+}
+
+// PrivateObjectSessionToken is an object session token signed with a private key wallet.
+type PrivateObjectSessionToken struct {
+	Signature    payload.Signature
+	SessionToken *session.Object
+	Wallet       *wallet.Account
+}
+
+func (m *PrivateObjectSessionToken) SetSignature(s payload.Signature) {
+	m.Signature = s
+}
+func (m PrivateObjectSessionToken) GetSignature() payload.Signature {
+	return m.Signature
+}
+func (m PrivateObjectSessionToken) InvalidAt(epoch uint64) bool {
+	return m.SessionToken.InvalidAt(epoch)
+}
+func (m PrivateObjectSessionToken) Lifetime() Lifetime {
+	l, _ := objectSessionLifetime(m.SessionToken)
+	return l
+}
+func (m PrivateObjectSessionToken) SignedData() []byte {
+	return m.SessionToken.SignedData()
+}
+func (m PrivateObjectSessionToken) Sign(issuerAddress string, signedPayload payload.Payload) error {
+	if signedPayload.Signature == nil {
+		return errors.New(utils.ErrorNoSignature)
+	}
+	decodedSignature, err := hex.DecodeString(signedPayload.Signature.HexSignature)
+	if err != nil {
+		fmt.Println("error signing ", err)
+		return err
+	}
+	bytesPublicKey, err := hex.DecodeString(signedPayload.Signature.HexPublicKey)
+	if err != nil {
+		return err
+	}
+	signature := refs.Signature{}
+	signature.SetSign(decodedSignature)
+	signature.SetScheme(refs.ECDSA_RFC6979_SHA256)
+	signature.SetKey(bytesPublicKey)
+
+	var b session2.Token
+	m.SessionToken.WriteToV2(&b) //convert the token to a v2 type
+	b.SetSignature(&signature)   //so that we can sign it
+	if err := m.SessionToken.ReadFromV2(b); err != nil {
+		fmt.Println("tried reading ", err)
+		return err
+	}
+	if !m.SessionToken.VerifySignature() {
+		return errors.New("token not signed")
+	}
+	return nil
+}
+
+// ObjectSession returns the object session of tok, if it is an object session token.
+func ObjectSession(tok Token) (*session.Object, bool) {
+	switch tok := tok.(type) {
+	case *ObjectSessionToken:
+		return tok.SessionToken, tok.SessionToken != nil
+	case *PrivateObjectSessionToken:
+		return tok.SessionToken, tok.SessionToken != nil
+	}
+	return nil, false
+}
@@ -0,0 +1,56 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/configwizard/sdk/database"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectSessionTokens(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	db := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("object session")))
+
+	manager := NewPrivateKeyTokenManager(gate)
+	require.NoError(t, manager.Persist(NewTokenStore(db, DeriveStoreKey(userAccount)), 1))
+	verb, ok := ObjectVerb(eacl.OperationPut)
+	require.True(t, ok)
+	tok, err := manager.NewObjectSessionToken(1, 1, 100, cnrID, verb, *userAccount.PublicKey())
+	require.NoError(t, err)
+	objectSession, ok := ObjectSession(tok)
+	require.True(t, ok)
+	require.True(t, objectSession.AssertContainer(cnrID))
+	require.Equal(t, user.ResolveFromECDSAPublicKey(userAccount.PrivateKey().PrivateKey.PublicKey), objectSession.Issuer())
+	require.NoError(t, objectSession.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	manager.AddSessionToken(userAccount.Address, cnrID.String(), tok)
+
+	containerSession := BuildUnsignedContainerSessionToken(1, 1, 100, cnrID, session.VerbContainerDelete, *gate.PublicKey())
+	manager.AddSessionToken(userAccount.Address, cnrID.String(), &ContainerSessionToken{SessionToken: containerSession})
+	require.Len(t, manager.SessionTokens, 2, "object and container sessions should not replace each other")
+
+	found, err := manager.FindObjectSessionToken(context.Background(), userAccount.Address, cnrID, 10, session.VerbObjectPut)
+	require.NoError(t, err)
+	require.Same(t, tok, found)
+	_, err = manager.FindObjectSessionToken(context.Background(), userAccount.Address, cnrID, 10, session.VerbObjectDelete)
+	require.Error(t, err, "the token is only for put")
+	require.Equal(t, Lifetime{Iat: 1, Nbf: 1, Exp: 100}, found.Lifetime())
+
+	restarted := NewPrivateKeyTokenManager(gate)
+	require.NoError(t, restarted.Persist(NewTokenStore(db, DeriveStoreKey(userAccount)), 10))
+	found, err = restarted.FindObjectSessionToken(context.Background(), userAccount.Address, cnrID, 10, session.VerbObjectPut)
+	require.NoError(t, err)
+	restored, ok := found.(*PrivateObjectSessionToken)
+	require.True(t, ok)
+	require.True(t, restored.SessionToken.VerifySignature())
+}
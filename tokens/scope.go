@@ -0,0 +1,157 @@
+package tokens
+
+import (
+	"fmt"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"sort"
+	"strings"
+)
+
+// requiredOperations are the operations an action needs a bearer token to allow. Reading, deleting and ranging head the
+// object first, and listing a container heads every object the search finds.
+var requiredOperations = map[eacl.Operation][]eacl.Operation{
+	eacl.OperationGet:       {eacl.OperationGet, eacl.OperationHead},
+	eacl.OperationHead:      {eacl.OperationHead},
+	eacl.OperationPut:       {eacl.OperationPut},
+	eacl.OperationDelete:    {eacl.OperationDelete, eacl.OperationHead},
+	eacl.OperationSearch:    {eacl.OperationSearch, eacl.OperationHead},
+	eacl.OperationRange:     {eacl.OperationRange, eacl.OperationHead},
+	eacl.OperationRangeHash: {eacl.OperationRangeHash, eacl.OperationHead},
+}
+
+// RequiredOperations returns the operations a token must allow for an action doing operation. An unknown operation
+// needs them all.
+func RequiredOperations(operation eacl.Operation) []eacl.Operation {
+	if ops, ok := requiredOperations[operation]; ok {
+		return append([]eacl.Operation(nil), ops...)
+	}
+	var ops []eacl.Operation
+	for op := eacl.OperationGet; op <= eacl.OperationRangeHash; op++ {
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Scope is what a bearer token is good for: operations in a container, and optionally only on one object.
+type Scope struct {
+	Container  cid.ID //zero for a token whose eACL is not bound to a container
+	Operations []eacl.Operation
+	Object     string //an object ID, empty for every object in the container
+}
+
+// BearerScope reads the scope of a bearer token from the operations its eACL allows. The token is limited to an object
+// if every allow record filters on the same object ID.
+func BearerScope(tok *bearer.Token) Scope {
+	var s Scope
+	if tok == nil {
+		return s
+	}
+	table := tok.EACLTable()
+	s.Container, _ = table.CID()
+	seen := make(map[eacl.Operation]bool)
+	objects := make(map[string]bool)
+	for _, r := range table.Records() {
+		if r.Action() != eacl.ActionAllow {
+			continue
+		}
+		if !seen[r.Operation()] {
+			seen[r.Operation()] = true
+			s.Operations = append(s.Operations, r.Operation())
+		}
+		object := ""
+		for _, f := range r.Filters() {
+			if f.Key() == eacl.FilterObjectID && f.Matcher() == eacl.MatchStringEqual {
+				object = f.Value()
+			}
+		}
+		objects[object] = true
+	}
+	if len(objects) == 1 {
+		for object := range objects {
+			s.Object = object
+		}
+	}
+	sort.Slice(s.Operations, func(i, j int) bool { return s.Operations[i] < s.Operations[j] })
+	return s
+}
+
+// Allows reports whether the scope allows operation.
+func (s Scope) Allows(operation eacl.Operation) bool {
+	for _, op := range s.Operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether a token with this scope can be used for everything in request.
+func (s Scope) Covers(request Scope) bool {
+	if s.Container != (cid.ID{}) && s.Container != request.Container {
+		return false
+	}
+	if s.Object != "" && s.Object != request.Object {
+		return false
+	}
+	for _, op := range request.Operations {
+		if !s.Allows(op) {
+			return false
+		}
+	}
+	return true
+}
+
+// narrower reports whether s gives away less than other: a single object over the container, then fewer operations.
+func (s Scope) narrower(other Scope) bool {
+	if (s.Object != "") != (other.Object != "") {
+		return s.Object != ""
+	}
+	return len(s.Operations) < len(other.Operations)
+}
+
+// String is the scope as it is used in the token managers' keys, e.g. GET,HEAD or GET,HEAD.<object ID>
+func (s Scope) String() string {
+	var ops []string
+	for _, op := range s.Operations {
+		ops = append(ops, op.EncodeToString())
+	}
+	if s.Object == "" {
+		return strings.Join(ops, ",")
+	}
+	return strings.Join(ops, ",") + "." + s.Object
+}
+
+// tokenKey is the key a token is kept under by the token managers and, prefixed by its group, in a TokenStore.
+// Bearer tokens are also keyed by their scope so that a container can have a token for each set of operations,
+// object session tokens by their verb.
+func tokenKey(address, cnrID string, tok Token) string {
+	key := fmt.Sprintf("%s.%s", address, cnrID)
+	switch tok := tok.(type) {
+	case *ObjectSessionToken:
+		return objectSessionKey(key, objectSessionVerb(tok.SessionToken))
+	case *PrivateObjectSessionToken:
+		return objectSessionKey(key, objectSessionVerb(tok.SessionToken))
+	}
+	if scope := tokenScope(tok); len(scope.Operations) > 0 {
+		key += "." + scope.String()
+	}
+	return key
+}
+
+func objectSessionKey(key string, verb session.ObjectVerb) string {
+	return fmt.Sprintf("%s.object.%d", key, verb)
+}
+
+// tokenScope is the scope of a bearer token, or nothing for other tokens.
+func tokenScope(tok Token) Scope {
+	switch tok := tok.(type) {
+	case *BearerToken:
+		return BearerScope(tok.BearerToken)
+	case *PrivateBearerToken:
+		return BearerScope(tok.BearerToken)
+	}
+	return Scope{}
+}
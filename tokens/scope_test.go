@@ -0,0 +1,81 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func scopedBearer(t *testing.T, signer *wallet.Account, cnrID cid.ID, object *oid.ID, ops ...eacl.Operation) *BearerToken {
+	table := InitTable(cnrID)
+	for _, op := range ops {
+		record := eacl.NewRecord()
+		record.SetOperation(op)
+		record.SetAction(eacl.ActionAllow)
+		if object != nil {
+			record.AddObjectIDFilter(eacl.MatchStringEqual, *object)
+		}
+		eacl.AddFormedTarget(record, eacl.RoleUser)
+		table.AddRecord(record)
+	}
+	var b bearer.Token
+	b.SetEACLTable(table)
+	b.SetIat(1)
+	b.SetNbf(1)
+	b.SetExp(100)
+	require.NoError(t, b.Sign(user.NewAutoIDSigner(signer.PrivateKey().PrivateKey)))
+	return &BearerToken{BearerToken: &b}
+}
+
+func TestBearerScope(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("scope")))
+	objID := oid.ID(sha256.Sum256([]byte("object")))
+
+	scope := BearerScope(scopedBearer(t, userAccount, cnrID, &objID, eacl.OperationHead, eacl.OperationGet).BearerToken)
+	require.Equal(t, []eacl.Operation{eacl.OperationGet, eacl.OperationHead}, scope.Operations)
+	require.Equal(t, objID.String(), scope.Object)
+	require.True(t, scope.Covers(Scope{Container: cnrID, Operations: RequiredOperations(eacl.OperationGet), Object: objID.String()}))
+	require.False(t, scope.Covers(Scope{Container: cnrID, Operations: RequiredOperations(eacl.OperationGet)}), "an object's token does not cover the container")
+	require.False(t, scope.Covers(Scope{Container: cnrID, Operations: RequiredOperations(eacl.OperationDelete), Object: objID.String()}))
+	require.Len(t, RequiredOperations(eacl.OperationUnknown), 7)
+}
+
+func TestFindNarrowestBearerToken(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("narrowest")))
+	objID := oid.ID(sha256.Sum256([]byte("object")))
+
+	manager := NewWalletConnectTokenManager(gate)
+	everything := scopedBearer(t, userAccount, cnrID, nil, RequiredOperations(eacl.OperationUnknown)...)
+	read := scopedBearer(t, userAccount, cnrID, nil, eacl.OperationGet, eacl.OperationHead)
+	readObject := scopedBearer(t, userAccount, cnrID, &objID, eacl.OperationGet, eacl.OperationHead)
+	for _, tok := range []*BearerToken{everything, read, readObject} {
+		manager.AddBearerToken(userAccount.Address, cnrID.String(), tok)
+	}
+	require.Len(t, manager.BearerTokens, 3, "each scope should be kept")
+
+	tok, err := manager.FindBearerToken(context.Background(), userAccount.Address, cnrID, 10, eacl.OperationGet)
+	require.NoError(t, err)
+	require.Same(t, read, tok)
+	tok, err = manager.FindScopedBearerToken(context.Background(), userAccount.Address, Scope{Container: cnrID, Operations: RequiredOperations(eacl.OperationGet), Object: objID.String()}, 10)
+	require.NoError(t, err)
+	require.Same(t, readObject, tok)
+	tok, err = manager.FindBearerToken(context.Background(), userAccount.Address, cnrID, 10, eacl.OperationPut)
+	require.NoError(t, err)
+	require.Same(t, everything, tok)
+	_, err = manager.FindBearerToken(context.Background(), userAccount.Address, cid.ID(sha256.Sum256([]byte("other"))), 10, eacl.OperationGet)
+	require.Error(t, err)
+}
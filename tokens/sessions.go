@@ -1,10 +1,12 @@
 package tokens
 
 import (
+	"crypto/ecdsa"
 	"github.com/google/uuid"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
 	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
@@ -23,35 +25,18 @@ import (
 //	return uint64(durationInEpochs)                     // (estimate)
 //}
 
+// BuildObjectSessionToken is BuildUnsignedObjectSessionToken signed by key.
 func BuildObjectSessionToken(key *keys.PrivateKey, lIat, lNbf, lExp uint64, verb session.ObjectVerb, cnrID cid.ID, gateSession *client.ResSessionCreate) (*session.Object, error) {
-
-	tok := new(session.Object)
-	tok.ForVerb(verb)
-	var idSession uuid.UUID
-	if err := idSession.UnmarshalBinary(gateSession.ID()); err != nil {
-		return nil, err
-	}
-	// decode session public key
-	var keySession neofsecdsa.PublicKey
-	if err := keySession.Decode(gateSession.PublicKey()); err != nil {
+	tok, err := BuildUnsignedObjectSessionToken(lIat, lNbf, lExp, verb, cnrID, gateSession)
+	if err != nil {
 		return nil, err
 	}
-	//tok.SetAuthKey((*neofsecdsa.PublicKey)(&gateKey)) //todo - who is this? I thought sessions could only be used by the owner? Who is key and who is gatekey?
-	tok.SetAuthKey(&keySession)
-	tok.SetID(idSession)
-	tok.SetIat(lIat) //is there a way to dynamically get these at runtime see CalculateEpochsForTime commented above. Can this be done?
-	tok.SetNbf(lNbf)
-	tok.SetExp(lExp)
-	tok.BindContainer(cnrID)
-
 	usrSigner := user.NewAutoIDSigner(key.PrivateKey)
 	return tok, tok.Sign(usrSigner)
 }
 
+// BuildUnsignedObjectSessionToken is an object session token for the session a node created, see client.SessionCreate.
 func BuildUnsignedObjectSessionToken(lIat, lNbf, lExp uint64, verb session.ObjectVerb, cnrID cid.ID, resSession *client.ResSessionCreate) (*session.Object, error) {
-
-	tok := new(session.Object)
-	tok.ForVerb(verb)
 	var idSession uuid.UUID
 	if err := idSession.UnmarshalBinary(resSession.ID()); err != nil {
 		return nil, err
@@ -61,14 +46,30 @@ func BuildUnsignedObjectSessionToken(lIat, lNbf, lExp uint64, verb session.Objec
 	if err := keySession.Decode(resSession.PublicKey()); err != nil {
 		return nil, err
 	}
-	tok.SetAuthKey(&keySession)
-	tok.SetID(idSession)
+	return objectSessionToken(lIat, lNbf, lExp, verb, cnrID, idSession, &keySession), nil
+}
+
+// BuildUnsignedGateObjectSessionToken is an object session token for the gate key, rather than a node's session key,
+// so the gate signs the objects it puts itself. The token is issued by the owner of issuerKey.
+func BuildUnsignedGateObjectSessionToken(lIat, lNbf, lExp uint64, verb session.ObjectVerb, cnrID cid.ID, gateKey, issuerKey keys.PublicKey) *session.Object {
+	tok := objectSessionToken(lIat, lNbf, lExp, verb, cnrID, uuid.New(), (*neofsecdsa.PublicKey)(&gateKey))
+	tok.SetIssuer(user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(issuerKey)))
+	return tok
+}
+
+// objectSessionToken is an object session token for authKey to act with in the container.
+func objectSessionToken(lIat, lNbf, lExp uint64, verb session.ObjectVerb, cnrID cid.ID, id uuid.UUID, authKey neofscrypto.PublicKey) *session.Object {
+	tok := new(session.Object)
+	tok.ForVerb(verb)
+	tok.BindContainer(cnrID)
+	tok.SetID(id)
+	tok.SetAuthKey(authKey)
 	tok.SetIat(lIat) //is there a way to dynamically get these at runtime see CalculateEpochsForTime commented above. Can this be done?
 	tok.SetNbf(lNbf)
 	tok.SetExp(lExp)
-	tok.BindContainer(cnrID)
-	return tok, nil
+	return tok
 }
+
 func BuildUnsignedContainerSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ContainerVerb, gateKey keys.PublicKey) *session.Container {
 	tok := new(session.Container)
 	tok.ForVerb(verb)
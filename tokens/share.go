@@ -0,0 +1,297 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/v2/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"strings"
+)
+
+// ExportVersion is the version of the Export format written by ExportToken. ImportToken refuses newer versions.
+const ExportVersion = 1
+
+// shareFragment is the URL fragment key ShareLink puts an exported token under.
+const shareFragment = "share="
+
+// Export is a signed token with what someone needs to know before using it. Private token types are exported as
+// their public counterparts, the binary token is the same.
+type Export struct {
+	Version    int      `json:"v"`
+	Kind       string   `json:"kind"`
+	Network    string   `json:"network"`
+	Container  string   `json:"container"`
+	Operations []string `json:"operations,omitempty"` //what a bearer or object session token allows
+	Object     string   `json:"object,omitempty"`     //the object a bearer token is limited to, if any
+	Expiry     uint64   `json:"exp"`
+	Issuer     string   `json:"issuer"`
+	Token      string   `json:"token"` //base64 of the binary token
+}
+
+// ExportToken encodes a signed token for network and container as a compact string: base64 (URL safe) of the Export.
+func ExportToken(tok Token, network utils.Network, cnrID cid.ID) (string, error) {
+	e := Export{Version: ExportVersion, Network: string(network), Container: cnrID.String(), Expiry: tok.Lifetime().Exp}
+	var binary []byte
+	switch tok := tok.(type) {
+	case *BearerToken:
+		e.Kind, binary = kindBearer, tok.BearerToken.Marshal()
+		if err := exportBearer(&e, tok.BearerToken, cnrID); err != nil {
+			return "", err
+		}
+	case *PrivateBearerToken:
+		e.Kind, binary = kindBearer, tok.BearerToken.Marshal()
+		if err := exportBearer(&e, tok.BearerToken, cnrID); err != nil {
+			return "", err
+		}
+	case *ContainerSessionToken:
+		e.Kind, binary = kindContainerSession, tok.SessionToken.Marshal()
+		if err := exportContainerSession(&e, tok.SessionToken, cnrID); err != nil {
+			return "", err
+		}
+	case *PrivateContainerSessionToken:
+		e.Kind, binary = kindContainerSession, tok.SessionToken.Marshal()
+		if err := exportContainerSession(&e, tok.SessionToken, cnrID); err != nil {
+			return "", err
+		}
+	case *ObjectSessionToken:
+		e.Kind, binary = kindObjectSession, tok.SessionToken.Marshal()
+		if err := exportObjectSession(&e, tok.SessionToken, cnrID); err != nil {
+			return "", err
+		}
+	case *PrivateObjectSessionToken:
+		e.Kind, binary = kindObjectSession, tok.SessionToken.Marshal()
+		if err := exportObjectSession(&e, tok.SessionToken, cnrID); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("cannot export a %T", tok)
+	}
+	e.Token = base64.StdEncoding.EncodeToString(binary)
+	byt, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(byt), nil
+}
+
+func exportBearer(e *Export, tok *bearer.Token, cnrID cid.ID) error {
+	if tok == nil || !tok.VerifySignature() {
+		return errors.New("only signed tokens can be exported")
+	}
+	if !tok.AssertContainer(cnrID) {
+		return fmt.Errorf("token is not for container %s", cnrID)
+	}
+	scope := BearerScope(tok)
+	for _, op := range scope.Operations {
+		e.Operations = append(e.Operations, op.EncodeToString())
+	}
+	e.Object = scope.Object
+	e.Issuer = tok.ResolveIssuer().EncodeToString()
+	return nil
+}
+
+func exportContainerSession(e *Export, tok *session.Container, cnrID cid.ID) error {
+	if tok == nil || !tok.VerifySignature() {
+		return errors.New("only signed tokens can be exported")
+	}
+	if !tok.AppliedTo(cnrID) {
+		return fmt.Errorf("token is not for container %s", cnrID)
+	}
+	e.Issuer = tok.Issuer().EncodeToString()
+	return nil
+}
+
+func exportObjectSession(e *Export, tok *session.Object, cnrID cid.ID) error {
+	if tok == nil || !tok.VerifySignature() {
+		return errors.New("only signed tokens can be exported")
+	}
+	if !tok.AssertContainer(cnrID) {
+		return fmt.Errorf("token is not for container %s", cnrID)
+	}
+	verb := objectSessionVerb(tok)
+	for op, v := range objectVerbs {
+		if v == verb {
+			e.Operations = append(e.Operations, op.EncodeToString())
+		}
+	}
+	e.Issuer = tok.Issuer().EncodeToString()
+	return nil
+}
+
+// ImportToken decodes a token exported by ExportToken for network. The token must be signed by its issuer and match
+// what the export says about it: container, issuer and expiry.
+func ImportToken(data string, network utils.Network) (Token, Export, error) {
+	var e Export
+	byt, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, e, fmt.Errorf("not an exported token: %w", err)
+	}
+	if err := json.Unmarshal(byt, &e); err != nil {
+		return nil, e, fmt.Errorf("not an exported token: %w", err)
+	}
+	if e.Version < 1 || e.Version > ExportVersion {
+		return nil, e, fmt.Errorf("unsupported token export version %d", e.Version)
+	}
+	if utils.Network(e.Network) != network {
+		return nil, e, fmt.Errorf("token is for %s, not %s", e.Network, network)
+	}
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(e.Container); err != nil {
+		return nil, e, fmt.Errorf("wrong container Id: %w", err)
+	}
+	binary, err := base64.StdEncoding.DecodeString(e.Token)
+	if err != nil {
+		return nil, e, err
+	}
+	var tok Token
+	var issuer user.ID
+	var issuerKey []byte
+	switch e.Kind {
+	case kindBearer:
+		var b bearer.Token
+		if err := b.Unmarshal(binary); err != nil {
+			return nil, e, err
+		}
+		if !b.VerifySignature() {
+			return nil, e, errors.New("token signature is not valid")
+		}
+		if !b.AssertContainer(cnrID) {
+			return nil, e, fmt.Errorf("token is not for container %s", cnrID)
+		}
+		tok, issuer, issuerKey = &BearerToken{BearerToken: &b}, b.ResolveIssuer(), b.SigningKeyBytes()
+	case kindContainerSession:
+		var c session.Container
+		if err := c.Unmarshal(binary); err != nil {
+			return nil, e, err
+		}
+		if !c.VerifySignature() {
+			return nil, e, errors.New("token signature is not valid")
+		}
+		if !c.AppliedTo(cnrID) {
+			return nil, e, fmt.Errorf("token is not for container %s", cnrID)
+		}
+		tok, issuer, issuerKey = &ContainerSessionToken{SessionToken: &c}, c.Issuer(), c.IssuerPublicKeyBytes()
+	case kindObjectSession:
+		var o session.Object
+		if err := o.Unmarshal(binary); err != nil {
+			return nil, e, err
+		}
+		if !o.VerifySignature() {
+			return nil, e, errors.New("token signature is not valid")
+		}
+		if !o.AssertContainer(cnrID) {
+			return nil, e, fmt.Errorf("token is not for container %s", cnrID)
+		}
+		tok, issuer, issuerKey = &ObjectSessionToken{SessionToken: &o}, o.Issuer(), o.IssuerPublicKeyBytes()
+	default:
+		return nil, e, errors.New("unknown token kind " + e.Kind)
+	}
+	if !signedByIssuer(issuer, issuerKey) {
+		return nil, e, errors.New("token was not signed by its issuer")
+	}
+	if issuer.EncodeToString() != e.Issuer {
+		return nil, e, fmt.Errorf("token was issued by %s, not %s", issuer, e.Issuer)
+	}
+	if tok.Lifetime().Exp != e.Expiry {
+		return nil, e, errors.New("token expiry does not match the export")
+	}
+	return tok, e, nil
+}
+
+// signedByIssuer reports whether key, the key a token was signed with, belongs to issuer.
+func signedByIssuer(issuer user.ID, key []byte) bool {
+	var pub neofsecdsa.PublicKey
+	if err := pub.Decode(key); err != nil {
+		return false
+	}
+	return user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(pub)).Equals(issuer)
+}
+
+// ShareOperations are what a shared bearer token allows: listing the container, heading and reading its objects.
+var ShareOperations = []eacl.Operation{eacl.OperationGet, eacl.OperationHead, eacl.OperationSearch}
+
+// ShareBearerToken is an unsigned bearer token from issuer letting the holder of recipient, and no one else, read the
+// container cnrID until exp. issuer signs it before it is shared with ShareLink.
+func ShareBearerToken(cnrID cid.ID, issuer user.ID, recipient keys.PublicKey, iat, exp uint64) bearer.Token {
+	var tok bearer.Token
+	tok.ForUser(user.ResolveFromECDSAPublicKey(ecdsa.PublicKey(recipient)))
+	tok.SetIssuer(issuer)
+	tok.SetIat(iat)
+	tok.SetNbf(iat)
+	tok.SetExp(exp)
+	var table eacl.Table
+	table.SetCID(cnrID)
+	for _, op := range ShareOperations {
+		record := eacl.NewRecord()
+		record.SetOperation(op)
+		record.SetAction(eacl.ActionAllow)
+		record.AddObjectContainerIDFilter(eacl.MatchStringEqual, cnrID)
+		eacl.AddFormedTarget(record, eacl.RoleUnknown, ecdsa.PublicKey(recipient))
+		table.AddRecord(record)
+	}
+	for op := eacl.OperationGet; op <= eacl.OperationRangeHash; op++ {
+		record := eacl.NewRecord()
+		record.SetOperation(op)
+		record.SetAction(eacl.ActionDeny)
+		record.AddObjectContainerIDFilter(eacl.MatchStringEqual, cnrID)
+		eacl.AddFormedTarget(record, eacl.RoleOthers)
+		table.AddRecord(record)
+	}
+	tok.SetEACLTable(table)
+	return tok
+}
+
+// ShareLink is base with a URL fragment holding the exported bearer token, e.g. for Greenfinch to open a shared
+// container. The fragment is never sent to a server. The token must be one made by ShareBearerToken: for one recipient
+// and only allowing ShareOperations.
+func ShareLink(base string, tok Token, network utils.Network, cnrID cid.ID) (string, error) {
+	var b *bearer.Token
+	switch tok := tok.(type) {
+	case *BearerToken:
+		b = tok.BearerToken
+	case *PrivateBearerToken:
+		b = tok.BearerToken
+	default:
+		return "", errors.New("only bearer tokens can be shared")
+	}
+	if b == nil || !bearerForUser(b) {
+		return "", errors.New("only tokens for a recipient can be shared")
+	}
+	for _, op := range BearerScope(b).Operations {
+		if !(Scope{Operations: ShareOperations}).Allows(op) {
+			return "", fmt.Errorf("token allows %s, only read access can be shared", op.EncodeToString())
+		}
+	}
+	data, err := ExportToken(tok, network, cnrID)
+	if err != nil {
+		return "", err
+	}
+	base, _, _ = strings.Cut(base, "#")
+	return base + "#" + shareFragment + data, nil
+}
+
+// bearerForUser reports whether tok can only be used by the user it was issued for.
+func bearerForUser(tok *bearer.Token) bool {
+	var m acl.BearerToken
+	tok.WriteToV2(&m)
+	return m.GetBody().GetOwnerID() != nil
+}
+
+// ParseShareLink returns the exported token in a link made by ShareLink, ready for ImportToken.
+func ParseShareLink(link string) (string, error) {
+	_, fragment, ok := strings.Cut(link, "#")
+	if !ok || !strings.HasPrefix(fragment, shareFragment) {
+		return "", errors.New("not a share link")
+	}
+	return strings.TrimPrefix(fragment, shareFragment), nil
+}
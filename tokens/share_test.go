@@ -0,0 +1,100 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/configwizard/sdk/utils"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportToken(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("share")))
+	read := scopedBearer(t, userAccount, cnrID, nil, eacl.OperationGet, eacl.OperationHead)
+
+	data, err := ExportToken(read, utils.TestNet, cnrID)
+	require.NoError(t, err)
+	tok, export, err := ImportToken(data, utils.TestNet)
+	require.NoError(t, err)
+	require.Equal(t, ExportVersion, export.Version)
+	require.Equal(t, cnrID.String(), export.Container)
+	require.Equal(t, []string{"GET", "HEAD"}, export.Operations)
+	require.Equal(t, uint64(100), export.Expiry)
+	require.Equal(t, user.ResolveFromECDSAPublicKey(userAccount.PrivateKey().PrivateKey.PublicKey).EncodeToString(), export.Issuer)
+	imported, ok := tok.(*BearerToken)
+	require.True(t, ok)
+	require.Equal(t, read.BearerToken.Marshal(), imported.BearerToken.Marshal())
+
+	_, _, err = ImportToken(data, utils.MainNet)
+	require.Error(t, err, "the token is for testnet")
+	_, err = ExportToken(read, utils.TestNet, cid.ID(sha256.Sum256([]byte("other"))))
+	require.Error(t, err, "the token is not for the other container")
+
+	other, err := wallet.NewAccount()
+	require.NoError(t, err)
+	tampered := export
+	tampered.Issuer = user.ResolveFromECDSAPublicKey(other.PrivateKey().PrivateKey.PublicKey).EncodeToString()
+	_, _, err = ImportToken(encodeExport(t, tampered), utils.TestNet)
+	require.Error(t, err, "the issuer does not match the token")
+	tampered = export
+	tampered.Expiry = 1000
+	_, _, err = ImportToken(encodeExport(t, tampered), utils.TestNet)
+	require.Error(t, err, "the expiry does not match the token")
+	tampered = export
+	tampered.Version = ExportVersion + 1
+	_, _, err = ImportToken(encodeExport(t, tampered), utils.TestNet)
+	require.Error(t, err)
+}
+
+func TestShareLink(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	recipient, err := wallet.NewAccount()
+	require.NoError(t, err)
+	cnrID := cid.ID(sha256.Sum256([]byte("share link")))
+	issuer := user.ResolveFromECDSAPublicKey(userAccount.PrivateKey().PrivateKey.PublicKey)
+	read := ShareBearerToken(cnrID, issuer, *recipient.PublicKey(), 1, 100)
+	require.NoError(t, read.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	require.True(t, read.AssertUser(user.ResolveFromECDSAPublicKey(recipient.PrivateKey().PrivateKey.PublicKey)))
+	require.Equal(t, ShareOperations, BearerScope(&read).Operations)
+
+	link, err := ShareLink("https://app.greenfinch.app/#old", &BearerToken{BearerToken: &read}, utils.TestNet, cnrID)
+	require.NoError(t, err)
+	require.Contains(t, link, "https://app.greenfinch.app/#share=")
+	data, err := ParseShareLink(link)
+	require.NoError(t, err)
+	_, export, err := ImportToken(data, utils.TestNet)
+	require.NoError(t, err)
+	require.Equal(t, cnrID.String(), export.Container)
+
+	_, err = ParseShareLink("https://app.greenfinch.app/")
+	require.Error(t, err)
+	_, err = ShareLink("https://app.greenfinch.app/", &ContainerSessionToken{}, utils.TestNet, cnrID)
+	require.Error(t, err, "only bearer tokens can be shared")
+	_, err = ShareLink("https://app.greenfinch.app/", scopedBearer(t, userAccount, cnrID, nil, eacl.OperationGet), utils.TestNet, cnrID)
+	require.Error(t, err, "a token for anyone holding it cannot be shared")
+	write := ShareBearerToken(cnrID, issuer, *recipient.PublicKey(), 1, 100)
+	table := write.EACLTable()
+	record := eacl.NewRecord()
+	record.SetOperation(eacl.OperationPut)
+	record.SetAction(eacl.ActionAllow)
+	table.AddRecord(record)
+	write.SetEACLTable(table)
+	require.NoError(t, write.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	_, err = ShareLink("https://app.greenfinch.app/", &BearerToken{BearerToken: &write}, utils.TestNet, cnrID)
+	require.Error(t, err, "only read access can be shared")
+}
+
+func encodeExport(t *testing.T, e Export) string {
+	byt, err := json.Marshal(e)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(byt)
+}
@@ -0,0 +1,405 @@
+package tokens
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/configwizard/sdk/database"
+	"github.com/configwizard/sdk/payload"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-api-go/v2/acl"
+	session2 "github.com/nspcc-dev/neofs-api-go/v2/session"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"io"
+	"strings"
+)
+
+// the kinds of token a TokenStore can keep
+const (
+	kindBearer                  = "bearer"
+	kindPrivateBearer           = "private_bearer"
+	kindContainerSession        = "container_session"
+	kindPrivateContainerSession = "private_container_session"
+	kindObjectSession           = "object_session"
+	kindPrivateObjectSession    = "private_object_session"
+)
+
+// gateKeyID is where the gate account is kept in the token bucket. Tokens are issued to the gate key, so they are only
+// any use after a restart if the gate account is the same.
+const gateKeyID = "gate_key"
+
+// Lifetime is the epochs a token was issued at, is valid from and expires after.
+type Lifetime struct {
+	Iat uint64 `json:"iat"`
+	Nbf uint64 `json:"nbf"`
+	Exp uint64 `json:"exp"`
+}
+
+// Expired reports whether the token can no longer be used at epoch.
+func (l Lifetime) Expired(epoch uint64) bool {
+	return l.Exp < epoch
+}
+
+// ValidAt reports whether the token can be used at epoch: issued and valid from no later than epoch, and not expired.
+func (l Lifetime) ValidAt(epoch uint64) bool {
+	return l.Iat <= epoch && l.Nbf <= epoch && !l.Expired(epoch)
+}
+
+// TokenLifetime reads the lifetime of any of our token types.
+func TokenLifetime(tok Token) (Lifetime, bool) {
+	var lt Lifetime
+	switch tok := tok.(type) {
+	case *BearerToken:
+		return bearerLifetime(tok.BearerToken)
+	case *PrivateBearerToken:
+		return bearerLifetime(tok.BearerToken)
+	case *ContainerSessionToken:
+		return sessionLifetime(tok.SessionToken)
+	case *PrivateContainerSessionToken:
+		return sessionLifetime(tok.SessionToken)
+	case *ObjectSessionToken:
+		return objectSessionLifetime(tok.SessionToken)
+	case *PrivateObjectSessionToken:
+		return objectSessionLifetime(tok.SessionToken)
+	}
+	return lt, false
+}
+
+func bearerLifetime(tok *bearer.Token) (Lifetime, bool) {
+	if tok == nil {
+		return Lifetime{}, false
+	}
+	var m acl.BearerToken
+	tok.WriteToV2(&m)
+	l := m.GetBody().GetLifetime()
+	return Lifetime{Iat: l.GetIat(), Nbf: l.GetNbf(), Exp: l.GetExp()}, true
+}
+
+func sessionLifetime(tok *session.Container) (Lifetime, bool) {
+	if tok == nil {
+		return Lifetime{}, false
+	}
+	var m session2.Token
+	tok.WriteToV2(&m)
+	l := m.GetBody().GetLifetime()
+	return Lifetime{Iat: l.GetIat(), Nbf: l.GetNbf(), Exp: l.GetExp()}, true
+}
+
+// storedToken is a token as it is kept in the database, signature included.
+type storedToken struct {
+	Kind      string            `json:"kind"`
+	Address   string            `json:"address"`
+	Container string            `json:"container"`
+	Token     []byte            `json:"token"`
+	Signature payload.Signature `json:"signature"`
+	Lifetime  Lifetime          `json:"lifetime"`
+}
+
+// sealed is what is written to the database, Data is encrypted when Encrypted is set.
+type sealed struct {
+	Encrypted bool   `json:"encrypted"`
+	Nonce     []byte `json:"nonce,omitempty"`
+	Data      []byte `json:"data"`
+}
+
+// TokenStore keeps signed tokens in the database.Store's TokenBucket, so they survive a restart. The store is already
+// scoped to the wallet and network it was registered for. With a Key the tokens, and the gate account they were issued
+// to, are encrypted at rest. Without one the tokens are stored in the clear and the gate account is not stored at all,
+// so they are only used again if the token manager is given the same gate account after the restart.
+type TokenStore struct {
+	Store database.Store
+	Key   []byte //32 bytes for AES-256-GCM, see DeriveStoreKey. Nil stores tokens in the clear
+}
+
+func NewTokenStore(store database.Store, key []byte) *TokenStore {
+	return &TokenStore{Store: store, Key: key}
+}
+
+// DeriveStoreKey derives a TokenStore key from a wallet account's private key. WalletConnect wallets have no private key
+// to hand and need a secret of their own.
+func DeriveStoreKey(a *wallet.Account) []byte {
+	mac := hmac.New(sha256.New, a.PrivateKey().Bytes())
+	mac.Write([]byte("neofs token store"))
+	return mac.Sum(nil)
+}
+
+func (s *TokenStore) seal(data []byte) ([]byte, error) {
+	if s.Key == nil {
+		return json.Marshal(sealed{Data: data})
+	}
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed{Encrypted: true, Nonce: nonce, Data: gcm.Seal(nil, nonce, data, nil)})
+}
+
+func (s *TokenStore) open(byt []byte) ([]byte, error) {
+	var v sealed
+	if err := json.Unmarshal(byt, &v); err != nil {
+		return nil, err
+	}
+	if !v.Encrypted {
+		return v.Data, nil
+	}
+	if s.Key == nil {
+		return nil, errors.New("token is encrypted and the store has no key")
+	}
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, v.Nonce, v.Data, nil)
+}
+
+func (s *TokenStore) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// tokenID is the manager's key for a token (see tokenKey), grouped by the kind of token.
+func tokenID(kind, key string) string {
+	group := "bearer"
+	if strings.HasSuffix(kind, "session") {
+		group = "session"
+	}
+	return fmt.Sprintf("%s.%s", group, key)
+}
+
+// Save stores tok for address and container, replacing any token stored for them, and the same scope, before.
+func (s *TokenStore) Save(address, cnrID string, tok Token) error {
+	st := storedToken{Address: address, Container: cnrID, Signature: tok.GetSignature()}
+	switch tok := tok.(type) {
+	case *BearerToken:
+		st.Kind, st.Token = kindBearer, tok.BearerToken.Marshal()
+	case *PrivateBearerToken:
+		st.Kind, st.Token = kindPrivateBearer, tok.BearerToken.Marshal()
+	case *ContainerSessionToken:
+		st.Kind, st.Token = kindContainerSession, tok.SessionToken.Marshal()
+	case *PrivateContainerSessionToken:
+		st.Kind, st.Token = kindPrivateContainerSession, tok.SessionToken.Marshal()
+	case *ObjectSessionToken:
+		st.Kind, st.Token = kindObjectSession, tok.SessionToken.Marshal()
+	case *PrivateObjectSessionToken:
+		st.Kind, st.Token = kindPrivateObjectSession, tok.SessionToken.Marshal()
+	default:
+		return fmt.Errorf("cannot store a %T", tok)
+	}
+	st.Lifetime, _ = TokenLifetime(tok)
+	byt, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if byt, err = s.seal(byt); err != nil {
+		return err
+	}
+	return s.Store.Update(database.TokenBucket, tokenID(st.Kind, tokenKey(address, cnrID, tok)), byt)
+}
+
+// Delete removes the bearer or session token the managers keep under key.
+func (s *TokenStore) Delete(session bool, key string) error {
+	kind := kindBearer
+	if session {
+		kind = kindContainerSession
+	}
+	return s.Store.Delete(database.TokenBucket, tokenID(kind, key))
+}
+
+// Load returns the stored bearer and session tokens, keyed as the token managers keep them, see tokenKey.
+// Tokens expired at epoch are deleted rather than returned. Tokens that cannot be read, e.g. with the wrong key, are skipped.
+// w is the account the private token types sign with.
+func (s *TokenStore) Load(epoch uint64, w *wallet.Account) (map[string]Token, map[string]Token, error) {
+	bearers, sessions := make(map[string]Token), make(map[string]Token)
+	all, err := s.Store.SelectAll(database.TokenBucket)
+	if err != nil {
+		if err.Error() == database.ErrorNotFound {
+			return bearers, sessions, nil
+		}
+		return nil, nil, err
+	}
+	for id, byt := range all {
+		if id == gateKeyID {
+			continue
+		}
+		tok, st, err := s.read(byt, w)
+		if err != nil {
+			//most likely the wrong key, the token may still be read with the right one
+			fmt.Println("could not read token ", id, err)
+			continue
+		}
+		if st.Lifetime.Expired(epoch) {
+			if err := s.Store.Delete(database.TokenBucket, id); err != nil {
+				fmt.Println("could not delete token ", id, err)
+			}
+			continue
+		}
+		key := tokenKey(st.Address, st.Container, tok)
+		switch st.Kind {
+		case kindBearer, kindPrivateBearer:
+			bearers[key] = tok
+		default:
+			sessions[key] = tok
+		}
+	}
+	return bearers, sessions, nil
+}
+
+func (s *TokenStore) read(byt []byte, w *wallet.Account) (Token, storedToken, error) {
+	var st storedToken
+	data, err := s.open(byt)
+	if err != nil {
+		return nil, st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, st, err
+	}
+	switch st.Kind {
+	case kindBearer, kindPrivateBearer:
+		var b bearer.Token
+		if err := b.Unmarshal(st.Token); err != nil {
+			return nil, st, err
+		}
+		if st.Kind == kindPrivateBearer {
+			return &PrivateBearerToken{BearerToken: &b, Signature: st.Signature, Wallet: w}, st, nil
+		}
+		return &BearerToken{BearerToken: &b, Signature: st.Signature}, st, nil
+	case kindContainerSession, kindPrivateContainerSession:
+		var c session.Container
+		if err := c.Unmarshal(st.Token); err != nil {
+			return nil, st, err
+		}
+		if st.Kind == kindPrivateContainerSession {
+			return &PrivateContainerSessionToken{SessionToken: &c, Signature: st.Signature, Wallet: w}, st, nil
+		}
+		return &ContainerSessionToken{SessionToken: &c, Signature: st.Signature}, st, nil
+	case kindObjectSession, kindPrivateObjectSession:
+		var o session.Object
+		if err := o.Unmarshal(st.Token); err != nil {
+			return nil, st, err
+		}
+		if st.Kind == kindPrivateObjectSession {
+			return &PrivateObjectSessionToken{SessionToken: &o, Signature: st.Signature, Wallet: w}, st, nil
+		}
+		return &ObjectSessionToken{SessionToken: &o, Signature: st.Signature}, st, nil
+	}
+	return nil, st, errors.New("unknown token kind " + st.Kind)
+}
+
+// GateAccount returns the gate account kept in the store, or keeps current and returns it if there is none yet.
+// The gate account's private key is only kept in a store with a Key, without one current is always returned.
+func (s *TokenStore) GateAccount(current *wallet.Account) (*wallet.Account, error) {
+	if s.Key == nil {
+		return current, nil
+	}
+	if byt, err := s.Store.Select(database.TokenBucket, gateKeyID); err == nil {
+		wif, err := s.open(byt)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.NewAccountFromWIF(string(wif))
+	}
+	byt, err := s.seal([]byte(current.PrivateKey().WIF()))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Store.Update(database.TokenBucket, gateKeyID, byt); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// saveToken saves to store if there is one. A token that cannot be saved is still usable until a restart.
+func saveToken(store *TokenStore, address, cnrID string, tok Token) {
+	if store == nil {
+		return
+	}
+	if err := store.Save(address, cnrID, tok); err != nil {
+		fmt.Println("could not persist token ", err)
+	}
+}
+
+// restoreTokens loads the gate account and unexpired tokens from store into the manager's maps.
+func restoreTokens(store *TokenStore, epoch uint64, current *wallet.Account, bearers, sessions map[string]Token) (*wallet.Account, error) {
+	w, err := store.GateAccount(current)
+	if err != nil {
+		return nil, err
+	}
+	storedBearers, storedSessions, err := store.Load(epoch, w)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range storedBearers {
+		if IssuedTo(v, w) {
+			bearers[k] = v
+		}
+	}
+	for k, v := range storedSessions {
+		if IssuedTo(v, w) {
+			sessions[k] = v
+		}
+	}
+	return w, nil
+}
+
+// IssuedTo reports whether gate can use tok: a bearer token for the gate's user, or one for any user, or a session
+// bound to the gate's key. Tokens stored in the clear may have been issued to a gate account that is not in use any more.
+func IssuedTo(tok Token, gate *wallet.Account) bool {
+	gateKey := gate.PrivateKey().PrivateKey.PublicKey
+	switch tok := tok.(type) {
+	case *BearerToken:
+		return tok.BearerToken.AssertUser(user.ResolveFromECDSAPublicKey(gateKey))
+	case *PrivateBearerToken:
+		return tok.BearerToken.AssertUser(user.ResolveFromECDSAPublicKey(gateKey))
+	case *ContainerSessionToken:
+		return tok.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(&gateKey))
+	case *PrivateContainerSessionToken:
+		return tok.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(&gateKey))
+	case *ObjectSessionToken:
+		return tok.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(&gateKey))
+	case *PrivateObjectSessionToken:
+		return tok.SessionToken.AssertAuthKey((*neofsecdsa.PublicKey)(&gateKey))
+	}
+	return false
+}
+
+// pruneTokens removes tokens expired at epoch from the maps and from store, if there is one.
+func pruneTokens(store *TokenStore, epoch uint64, bearers, sessions map[string]Token) {
+	for _, tokens := range []map[string]Token{bearers, sessions} {
+		for key, tok := range tokens {
+			lifetime, ok := TokenLifetime(tok)
+			if !ok || !lifetime.Expired(epoch) {
+				continue
+			}
+			delete(tokens, key)
+			if store == nil {
+				continue
+			}
+			if err := store.Delete(isSession(tok), key); err != nil {
+				fmt.Println("could not delete token ", key, err)
+			}
+		}
+	}
+}
+
+func isSession(tok Token) bool {
+	switch tok.(type) {
+	case *ContainerSessionToken, *PrivateContainerSessionToken, *ObjectSessionToken, *PrivateObjectSessionToken:
+		return true
+	}
+	return false
+}
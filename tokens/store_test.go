@@ -0,0 +1,143 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/configwizard/sdk/database"
+	"github.com/configwizard/sdk/payload"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func testBearer(t *testing.T, signer *wallet.Account, cnrID cid.ID, exp uint64) *bearer.Token {
+	var b bearer.Token
+	table := AddRecords(InitTable(cnrID), []eacl.Target{*eacl.NewTarget()}, map[eacl.Operation]eacl.Action{eacl.OperationGet: eacl.ActionAllow, eacl.OperationHead: eacl.ActionAllow})
+	b.SetEACLTable(table)
+	b.SetIat(1)
+	b.SetNbf(1)
+	b.SetExp(exp)
+	require.NoError(t, b.Sign(user.NewAutoIDSigner(signer.PrivateKey().PrivateKey)))
+	return &b
+}
+
+func TestTokenStoreReloadsAndPrunes(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	db := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("store")))
+	expiredID := cid.ID(sha256.Sum256([]byte("expired")))
+
+	manager := NewWalletConnectTokenManager(gate)
+	require.NoError(t, manager.Persist(NewTokenStore(db, DeriveStoreKey(userAccount)), 5))
+
+	signature := payload.Signature{HexSignature: "00", HexPublicKey: userAccount.PublicKey().StringCompressed()}
+	manager.AddBearerToken(userAccount.Address, cnrID.String(), &BearerToken{BearerToken: testBearer(t, userAccount, cnrID, 100), Signature: signature})
+	manager.AddBearerToken(userAccount.Address, expiredID.String(), &BearerToken{BearerToken: testBearer(t, userAccount, expiredID, 10)})
+	sessionToken := BuildUnsignedContainerSessionToken(1, 1, 100, cnrID, session.VerbContainerPut, *gate.PublicKey())
+	require.NoError(t, sessionToken.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	manager.AddSessionToken(userAccount.Address, cnrID.String(), &ContainerSessionToken{SessionToken: sessionToken})
+
+	//a restart, with a new ephemeral gate key
+	newGate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	restarted := NewWalletConnectTokenManager(newGate)
+	require.NoError(t, restarted.Persist(NewTokenStore(db, DeriveStoreKey(userAccount)), 20))
+	require.Equal(t, gate.Address, restarted.W.Address, "the gate key the tokens were issued to should be restored")
+
+	tok, err := restarted.FindBearerToken(context.Background(), userAccount.Address, cnrID, 20, eacl.OperationGet)
+	require.NoError(t, err)
+	restored := tok.(*BearerToken)
+	require.True(t, restored.VerifySignature(), "the signature should survive")
+	require.Equal(t, signature, restored.GetSignature())
+	_, err = restarted.FindBearerToken(context.Background(), userAccount.Address, expiredID, 20, eacl.OperationGet)
+	require.Error(t, err, "expired tokens should not be reloaded")
+	require.Len(t, restarted.SessionTokens, 1)
+
+	stored, err := db.SelectAll(database.TokenBucket)
+	require.NoError(t, err)
+	require.Len(t, stored, 3, "the gate key, a bearer and a session token should be left")
+
+	restarted.Prune(200)
+	require.Empty(t, restarted.BearerTokens)
+	require.Empty(t, restarted.SessionTokens)
+	stored, err = db.SelectAll(database.TokenBucket)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+}
+
+func TestTokenStoreEncryption(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	db := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("encrypted")))
+	store := NewTokenStore(db, DeriveStoreKey(userAccount))
+	tok := &BearerToken{BearerToken: testBearer(t, userAccount, cnrID, 100)}
+	require.NoError(t, store.Save(userAccount.Address, cnrID.String(), tok))
+
+	byt, err := db.Select(database.TokenBucket, tokenID(kindBearer, tokenKey(userAccount.Address, cnrID.String(), tok)))
+	require.NoError(t, err)
+	require.NotContains(t, string(byt), userAccount.Address, "nothing should be stored in the clear")
+
+	bearers, _, err := store.Load(1, nil)
+	require.NoError(t, err)
+	require.Len(t, bearers, 1)
+
+	other, err := wallet.NewAccount()
+	require.NoError(t, err)
+	bearers, _, err = NewTokenStore(db, DeriveStoreKey(other)).Load(1, nil)
+	require.NoError(t, err)
+	require.Empty(t, bearers, "the wrong key cannot read the tokens")
+}
+
+func TestTokenStoreInTheClear(t *testing.T) {
+	userAccount, err := wallet.NewAccount()
+	require.NoError(t, err)
+	gate, err := wallet.NewAccount()
+	require.NoError(t, err)
+	db := database.NewMockDB(database.TestnetBucket, "wallet", "wallet")
+	cnrID := cid.ID(sha256.Sum256([]byte("clear")))
+
+	manager := NewPrivateKeyTokenManager(gate)
+	require.NoError(t, manager.Persist(NewTokenStore(db, nil), 1))
+	_, err = db.Select(database.TokenBucket, gateKeyID)
+	require.Error(t, err, "the gate key should not be stored in the clear")
+
+	forGate := testBearer(t, userAccount, cnrID, 100)
+	forGate.ForUser(user.ResolveFromECDSAPublicKey(gate.PrivateKey().PrivateKey.PublicKey))
+	require.NoError(t, forGate.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	manager.AddBearerToken(userAccount.Address, cnrID.String(), &BearerToken{BearerToken: forGate})
+	sessionToken := BuildUnsignedContainerSessionToken(1, 1, 100, cnrID, session.VerbContainerPut, *gate.PublicKey())
+	require.NoError(t, sessionToken.Sign(user.NewAutoIDSigner(userAccount.PrivateKey().PrivateKey)))
+	manager.AddSessionToken(userAccount.Address, cnrID.String(), &ContainerSessionToken{SessionToken: sessionToken})
+
+	stored, err := db.SelectAll(database.TokenBucket)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for _, byt := range stored {
+		require.Contains(t, string(byt), `"encrypted":false`)
+	}
+
+	//a restart with the same gate account, which the app kept itself
+	restarted := NewPrivateKeyTokenManager(gate)
+	require.NoError(t, restarted.Persist(NewTokenStore(db, nil), 10))
+	require.Equal(t, gate.Address, restarted.W.Address)
+	require.Len(t, restarted.BearerTokens, 1)
+	require.Len(t, restarted.SessionTokens, 1)
+
+	//and with another, which the tokens were not issued to
+	other, err := wallet.NewAccount()
+	require.NoError(t, err)
+	elsewhere := NewPrivateKeyTokenManager(other)
+	require.NoError(t, elsewhere.Persist(NewTokenStore(db, nil), 10))
+	require.Empty(t, elsewhere.BearerTokens)
+	require.Empty(t, elsewhere.SessionTokens)
+}
@@ -1,6 +1,7 @@
 package tokens
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
@@ -34,6 +35,7 @@ const (
 
 type Token interface {
 	InvalidAt(epoch uint64) bool
+	Lifetime() Lifetime //the epochs the token is valid between
 	Sign(issuerAddress string, p payload.Payload) error
 	GetSignature() payload.Signature
 	SetSignature(signature payload.Signature)
@@ -53,7 +55,11 @@ func (m PrivateContainerSessionToken) GetSignature() payload.Signature {
 	return m.Signature
 }
 func (m PrivateContainerSessionToken) InvalidAt(epoch uint64) bool {
-	return false
+	return m.SessionToken.InvalidAt(epoch)
+}
+func (m PrivateContainerSessionToken) Lifetime() Lifetime {
+	l, _ := sessionLifetime(m.SessionToken)
+	return l
 }
 func (m PrivateContainerSessionToken) Sign(issuerAddress string, signedPayload payload.Payload) error {
 	decodedSignature, err := hex.DecodeString(signedPayload.Signature.HexSignature)
@@ -101,7 +107,11 @@ func (m PrivateBearerToken) GetSignature() payload.Signature {
 	return m.Signature
 }
 func (m PrivateBearerToken) InvalidAt(epoch uint64) bool {
-	return false
+	return m.BearerToken.InvalidAt(epoch)
+}
+func (m PrivateBearerToken) Lifetime() Lifetime {
+	l, _ := bearerLifetime(m.BearerToken)
+	return l
 }
 
 /*
@@ -145,45 +155,78 @@ func (m PrivateBearerToken) SignedData() []byte {
 }
 
 type PrivateKeyTokenManager struct {
-	BearerTokens  map[string]Token //Will be loaded from database if we want to keep sessions across closures.
+	BearerTokens  map[string]Token //loaded from Store by Persist, if we want to keep sessions across closures.
 	SessionTokens map[string]Token
 	W             *wallet.Account
 	HaveToken     bool
-	mutex         sync.Mutex // Add a mutex to the struct
+	Store         *TokenStore //set by Persist, tokens added after are saved to it
+	Epochs        *EpochCache //the current epoch tokens are looked up at, the epoch passed to the Find methods if nil
+	SafetyMargin  uint64      //tokens expiring within this many epochs are evicted rather than found
+	mutex         sync.Mutex  // Add a mutex to the struct
 }
 
 func (t PrivateKeyTokenManager) Type() string {
 	return TypePrivateTokenManager
 }
 
-func NewPrivateKeyTokenManager(a *wallet.Account, persist bool) PrivateKeyTokenManager {
-	return PrivateKeyTokenManager{W: a, BearerTokens: make(map[string]Token), SessionTokens: make(map[string]Token)}
+func NewPrivateKeyTokenManager(a *wallet.Account) PrivateKeyTokenManager {
+	return PrivateKeyTokenManager{W: a, BearerTokens: make(map[string]Token), SessionTokens: make(map[string]Token), SafetyMargin: DefaultSafetyMargin}
 }
 
 func (t *PrivateKeyTokenManager) AddBearerToken(address string, cnrID string, b Token) {
 	t.mutex.Lock()         // Lock the mutex before modifying the map
 	defer t.mutex.Unlock() // Ensure the mutex is unlocked after modifying
-	t.BearerTokens[fmt.Sprintf("%s.%s", address, cnrID)] = b
+	t.BearerTokens[tokenKey(address, cnrID, b)] = b
+	saveToken(t.Store, address, cnrID, b)
 }
 func (t *PrivateKeyTokenManager) AddSessionToken(address, cnrID string, b Token) {
 	t.mutex.Lock()         // Lock the mutex before modifying the map
 	defer t.mutex.Unlock() // Ensure the mutex is unlocked after modifying
-	t.SessionTokens[fmt.Sprintf("%s.%s", address, cnrID)] = b
+	t.SessionTokens[tokenKey(address, cnrID, b)] = b
+	saveToken(t.Store, address, cnrID, b)
+}
+
+// Persist keeps tokens in store from now on and loads those already there that have not expired at epoch.
+// The gate account is swapped for the one in the store, as the stored tokens were issued to it.
+func (t *PrivateKeyTokenManager) Persist(store *TokenStore, epoch uint64) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	w, err := restoreTokens(store, epoch, t.W, t.BearerTokens, t.SessionTokens)
+	if err != nil {
+		return err
+	}
+	t.W = w
+	t.Store = store
+	return nil
 }
 
-// FindBearerToken should see if we have a valid token to do the job. If not create a new one.
-func (t PrivateKeyTokenManager) FindContainerSessionToken(address string, id cid.ID, epoch uint64) (Token, error) {
-	if tok, ok := t.SessionTokens[fmt.Sprintf("%s.%s", address, id)]; ok && tok.InvalidAt(1) {
-		tok, ok := tok.(*ContainerSessionToken)
-		if !ok {
+// SetEpochs looks tokens up at the epoch from epochs. Set it before the manager is in use.
+func (t *PrivateKeyTokenManager) SetEpochs(epochs *EpochCache) {
+	t.Epochs = epochs
+}
+
+// Prune forgets tokens that have expired at epoch, in memory and in the store.
+func (t *PrivateKeyTokenManager) Prune(epoch uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	pruneTokens(t.Store, epoch, t.BearerTokens, t.SessionTokens)
+}
+
+// FindContainerSessionToken returns the session token for the container if it can be used at the current epoch.
+func (t *PrivateKeyTokenManager) FindContainerSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	tok, err := usableToken(t.Store, t.SessionTokens, fmt.Sprintf("%s.%s", address, id), epoch, t.SafetyMargin)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tok.(*PrivateContainerSessionToken); !ok {
+		if _, ok := tok.(*ContainerSessionToken); !ok {
 			return nil, errors.New("no session token")
 		}
-		if tok.InvalidAt(epoch) {
-			return tok, errors.New(utils.ErrorNoToken)
-		}
-		return tok, nil
 	}
-	return nil, errors.New(utils.ErrorNoToken)
+	return tok, nil
 }
 
 func (t PrivateKeyTokenManager) NewSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ContainerVerb, issuerKey keys.PublicKey) (Token, error) {
@@ -206,6 +249,23 @@ func (t PrivateKeyTokenManager) NewSessionToken(lIat, lNbf, lExp uint64, cnrID c
 	}, nil
 }
 
+// NewObjectSessionToken is an object session token for the gate key to act as the owner of issuerKey in the container.
+func (t PrivateKeyTokenManager) NewObjectSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ObjectVerb, issuerKey keys.PublicKey) (Token, error) {
+	return &PrivateObjectSessionToken{
+		SessionToken: BuildUnsignedGateObjectSessionToken(lIat, lNbf, lExp, verb, cnrID, *t.W.PublicKey(), issuerKey),
+		Wallet:       t.W,
+	}, nil
+}
+
+// FindObjectSessionToken returns the object session token for verb in the container if it can be used at the current epoch.
+func (t *PrivateKeyTokenManager) FindObjectSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64, verb session.ObjectVerb) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := objectSessionKey(fmt.Sprintf("%s.%s", address, id), verb)
+	return usableToken(t.Store, t.SessionTokens, key, epoch, t.SafetyMargin)
+}
+
 func (t PrivateKeyTokenManager) PopulatePrivateBearerToken(bt bearer.Token) PrivateBearerToken {
 	return PrivateBearerToken{BearerToken: &bt, Wallet: t.W}
 }
@@ -219,31 +279,26 @@ func (t PrivateKeyTokenManager) NewBearerToken(table eacl.Table, lIat, lNbf, lEx
 	bearerToken.SetNbf(lNbf)
 	return &PrivateBearerToken{Wallet: t.W, BearerToken: &bearerToken}, nil
 }
-func (t PrivateKeyTokenManager) FindBearerToken(address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
 
-	if tok, ok := t.BearerTokens[fmt.Sprintf("%s.%s", address, id)]; !ok || tok.InvalidAt(1) {
+// FindBearerToken returns the narrowest bearer token for the container that can be used at the current epoch for an
+// action doing operation on any object, see RequiredOperations.
+func (t *PrivateKeyTokenManager) FindBearerToken(ctx context.Context, address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
+	return t.FindScopedBearerToken(ctx, address, Scope{Container: id, Operations: RequiredOperations(operation)}, epoch)
+}
+
+// FindScopedBearerToken returns the bearer token with the narrowest scope that covers scope and can be used at the current epoch.
+func (t *PrivateKeyTokenManager) FindScopedBearerToken(ctx context.Context, address string, scope Scope, epoch uint64) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	tok, err := narrowestBearer(t.Store, t.BearerTokens, address, scope, epoch, t.SafetyMargin)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tok.(*PrivateBearerToken); !ok {
 		return nil, errors.New(utils.ErrorNoToken)
-	} else {
-		tok, ok := tok.(*PrivateBearerToken)
-		if !ok {
-			return nil, errors.New(utils.ErrorNoToken)
-		}
-		bearerToken := tok.BearerToken
-		// we now need to check the rules the token needs to have
-		if !bearerToken.AssertContainer(id) {
-			return nil, errors.New(utils.ErrorNoToken)
-		}
-		if tok.InvalidAt(epoch) { //fix me unnecessary
-			return tok, errors.New(utils.ErrorNoToken)
-		}
-		records := bearerToken.EACLTable().Records()
-		for _, v := range records {
-			if v.Operation() == operation && v.Action() == eacl.ActionAllow {
-				return tok, nil
-			}
-		}
 	}
-	return nil, errors.New(utils.ErrorNoToken)
+	return tok, nil
 }
 
 func (t PrivateKeyTokenManager) GateKey() wallet.Account {
@@ -264,6 +319,10 @@ func (m ContainerSessionToken) GetSignature() payload.Signature {
 func (s ContainerSessionToken) InvalidAt(epoch uint64) bool {
 	return s.SessionToken.InvalidAt(epoch)
 }
+func (s ContainerSessionToken) Lifetime() Lifetime {
+	l, _ := sessionLifetime(s.SessionToken)
+	return l
+}
 
 func (s ContainerSessionToken) SignedData() []byte {
 	return s.SessionToken.SignedData()
@@ -373,6 +432,10 @@ func (b BearerToken) VerifySignature() bool {
 func (b BearerToken) InvalidAt(epoch uint64) bool {
 	return b.BearerToken.InvalidAt(epoch)
 }
+func (b BearerToken) Lifetime() Lifetime {
+	l, _ := bearerLifetime(b.BearerToken)
+	return l
+}
 
 func (b BearerToken) SignedData() []byte {
 	return b.BearerToken.SignedData()
@@ -399,7 +462,11 @@ func (t MockTokenManager) GateKey() wallet.Account {
 func (t MockTokenManager) AddBearerToken(address, cnrID string, b Token) {
 }
 
-func (t MockTokenManager) FindBearerToken(address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
+func (t MockTokenManager) FindBearerToken(ctx context.Context, address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
+	var bearerToken bearer.Token
+	return &BearerToken{BearerToken: &bearerToken}, nil
+}
+func (t MockTokenManager) FindScopedBearerToken(ctx context.Context, address string, scope Scope, epoch uint64) (Token, error) {
 	var bearerToken bearer.Token
 	return &BearerToken{BearerToken: &bearerToken}, nil
 }
@@ -409,7 +476,7 @@ func (t MockTokenManager) NewBearerToken(table eacl.Table, lIat, lNbf, lExp uint
 }
 func (t MockTokenManager) AddSessionToken(address, cnrID string, b Token) {
 }
-func (t MockTokenManager) FindContainerSessionToken(address string, id cid.ID, epoch uint64) (Token, error) {
+func (t MockTokenManager) FindContainerSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64) (Token, error) {
 	sessionToken := new(session.Container)
 	return &ContainerSessionToken{
 		SessionToken: sessionToken,
@@ -421,16 +488,29 @@ func (t MockTokenManager) NewSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID,
 		SessionToken: sessionToken,
 	}, nil
 }
+func (t MockTokenManager) NewObjectSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ObjectVerb, issuerKey keys.PublicKey) (Token, error) {
+	return &ObjectSessionToken{
+		SessionToken: new(session.Object),
+	}, nil
+}
+func (t MockTokenManager) FindObjectSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64, verb session.ObjectVerb) (Token, error) {
+	return &ObjectSessionToken{
+		SessionToken: new(session.Object),
+	}, nil
+}
 
 // WalletConnectTokenManager is responsible for keeping track of all valid sessions so not to need to resign every time
 // for now just bearer tokens, for object actions, containers use sessions and will sign for each action
 // listing containers does not need a token
 type WalletConnectTokenManager struct {
 	Persisted     bool             //use a fake/mock token for the time being that matches the mock emitter's signatures (todo - clean this up)Z
-	BearerTokens  map[string]Token //Will be loaded from database if we want to keep sessions across closures.
+	BearerTokens  map[string]Token //loaded from Store by Persist, if we want to keep sessions across closures.
 	SessionTokens map[string]Token //fixme - can this all be one in memory token store or do they need to be seperated
 	W             *wallet.Account
-	mutex         sync.Mutex // Add a mutex to the struct
+	Store         *TokenStore //set by Persist, tokens added after are saved to it
+	Epochs        *EpochCache //the current epoch tokens are looked up at, the epoch passed to the Find methods if nil
+	SafetyMargin  uint64      //tokens expiring within this many epochs are evicted rather than found
+	mutex         sync.Mutex  // Add a mutex to the struct
 
 }
 
@@ -438,8 +518,8 @@ func (t WalletConnectTokenManager) Type() string {
 	return TypeWCTokenManager
 }
 
-func NewWalletConnectTokenManager(a *wallet.Account, persist bool) WalletConnectTokenManager {
-	return WalletConnectTokenManager{W: a, BearerTokens: make(map[string]Token), SessionTokens: make(map[string]Token), Persisted: persist}
+func NewWalletConnectTokenManager(a *wallet.Account) WalletConnectTokenManager {
+	return WalletConnectTokenManager{W: a, BearerTokens: make(map[string]Token), SessionTokens: make(map[string]Token), SafetyMargin: DefaultSafetyMargin}
 }
 
 func (t WalletConnectTokenManager) GateKey() wallet.Account {
@@ -449,37 +529,57 @@ func (t WalletConnectTokenManager) GateKey() wallet.Account {
 func (t *WalletConnectTokenManager) AddBearerToken(address, cnrID string, b Token) {
 	t.mutex.Lock()         // Lock the mutex before modifying the map
 	defer t.mutex.Unlock() // Ensure the mutex is unlocked after modifying
-	t.BearerTokens[fmt.Sprintf("%s.%s", address, cnrID)] = b
+	t.BearerTokens[tokenKey(address, cnrID, b)] = b
+	saveToken(t.Store, address, cnrID, b)
 }
 
-// FindBearerToken should see if we have a valid token to do the job. If not create a new one.
-func (t WalletConnectTokenManager) FindBearerToken(address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
-	fmt.Println("looking for bearer for action ", operation, t.BearerTokens)
-	if tok, ok := t.BearerTokens[fmt.Sprintf("%s.%s", address, id)]; ok {
-		tok, ok := tok.(*BearerToken)
-		if !ok {
-			return nil, errors.New("no beaer token")
-		}
-		fmt.Println("and... its a bearer token. Lets go!")
-		bearerToken := tok.BearerToken
-		// we now need to check the rules the token needs to have
-		if !bearerToken.AssertContainer(id) {
-			return nil, errors.New(utils.ErrorNoToken)
-		}
-		//if tok.InvalidAt(epoch) {
-		//	return tok, errors.New(utils.ErrorNoToken)
-		//}
-		return tok, nil
-		records := bearerToken.EACLTable().Records()
-		for _, v := range records {
-			if v.Operation() == operation && v.Action() == eacl.ActionAllow {
-				fmt.Println("returning/found action allow for operation ", v.Operation())
-				return tok, nil
-			}
-		}
-		return nil, errors.New(utils.ErrorNoToken)
+// Persist keeps tokens in store from now on and loads those already there that have not expired at epoch, so
+// the user does not need to sign again after a restart. The gate account is swapped for the one in the store,
+// as the stored tokens were issued to it.
+func (t *WalletConnectTokenManager) Persist(store *TokenStore, epoch uint64) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	w, err := restoreTokens(store, epoch, t.W, t.BearerTokens, t.SessionTokens)
+	if err != nil {
+		return err
+	}
+	t.W = w
+	t.Store = store
+	return nil
+}
+
+// SetEpochs looks tokens up at the epoch from epochs. Set it before the manager is in use.
+func (t *WalletConnectTokenManager) SetEpochs(epochs *EpochCache) {
+	t.Epochs = epochs
+}
+
+// Prune forgets tokens that have expired at epoch, in memory and in the store.
+func (t *WalletConnectTokenManager) Prune(epoch uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	pruneTokens(t.Store, epoch, t.BearerTokens, t.SessionTokens)
+}
+
+// FindBearerToken returns the narrowest bearer token for the container that can be used at the current epoch for an
+// action doing operation on any object, see RequiredOperations.
+func (t *WalletConnectTokenManager) FindBearerToken(ctx context.Context, address string, id cid.ID, epoch uint64, operation eacl.Operation) (Token, error) {
+	fmt.Println("looking for bearer for action ", operation)
+	return t.FindScopedBearerToken(ctx, address, Scope{Container: id, Operations: RequiredOperations(operation)}, epoch)
+}
+
+// FindScopedBearerToken returns the bearer token with the narrowest scope that covers scope and can be used at the current epoch.
+func (t *WalletConnectTokenManager) FindScopedBearerToken(ctx context.Context, address string, scope Scope, epoch uint64) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	tok, err := narrowestBearer(t.Store, t.BearerTokens, address, scope, epoch, t.SafetyMargin)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tok.(*BearerToken); !ok {
+		return nil, errors.New("no bearer token")
 	}
-	return nil, errors.New(utils.ErrorNoToken)
+	return tok, nil
 }
 
 // NewBearerToken - if we don't have a valid bearer token, we'll need to create a new one.
@@ -508,27 +608,39 @@ func (t *WalletConnectTokenManager) WrapToken(token bearer.Token) Token {
 func (t *WalletConnectTokenManager) AddSessionToken(address, cnrID string, b Token) {
 	t.mutex.Lock()         // Lock the mutex before modifying the map
 	defer t.mutex.Unlock() // Ensure the mutex is unlocked after modifying
-	t.SessionTokens[fmt.Sprintf("%s.%s", address, cnrID)] = b
+	t.SessionTokens[tokenKey(address, cnrID, b)] = b
+	saveToken(t.Store, address, cnrID, b)
 }
 
-// FindBearerToken should see if we have a valid token to do the job. If not create a new one.
-func (t WalletConnectTokenManager) FindContainerSessionToken(address string, id cid.ID, epoch uint64) (Token, error) {
-	if tok, ok := t.SessionTokens[fmt.Sprintf("%s.%s", address, id)]; ok && tok.InvalidAt(1) {
-		tok, ok := tok.(*ContainerSessionToken)
-		if !ok {
-			return nil, errors.New("no session token")
-		}
-		// we now need to check the rules the token needs to have
-		//if !sessionToken.Ass.AssertContainer(id) {
-		//	return BearerToken{}, errors.New(utils.ErrorNoToken)
-		//}
-		//we can check a verb if we like.
-		if tok.InvalidAt(epoch) {
-			return tok, errors.New(utils.ErrorNoToken)
-		}
-		return tok, nil
+// FindContainerSessionToken returns the session token for the container if it can be used at the current epoch.
+func (t *WalletConnectTokenManager) FindContainerSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	tok, err := usableToken(t.Store, t.SessionTokens, fmt.Sprintf("%s.%s", address, id), epoch, t.SafetyMargin)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New(utils.ErrorNoToken)
+	if _, ok := tok.(*ContainerSessionToken); !ok {
+		return nil, errors.New("no session token")
+	}
+	return tok, nil
+}
+
+// NewObjectSessionToken is an object session token for the gate key to act as the owner of issuerKey in the container.
+func (t WalletConnectTokenManager) NewObjectSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ObjectVerb, issuerKey keys.PublicKey) (Token, error) {
+	return &ObjectSessionToken{
+		SessionToken: BuildUnsignedGateObjectSessionToken(lIat, lNbf, lExp, verb, cnrID, *t.W.PublicKey(), issuerKey),
+	}, nil
+}
+
+// FindObjectSessionToken returns the object session token for verb in the container if it can be used at the current epoch.
+func (t *WalletConnectTokenManager) FindObjectSessionToken(ctx context.Context, address string, id cid.ID, epoch uint64, verb session.ObjectVerb) (Token, error) {
+	epoch = currentEpoch(ctx, t.Epochs, epoch)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := objectSessionKey(fmt.Sprintf("%s.%s", address, id), verb)
+	return usableToken(t.Store, t.SessionTokens, key, epoch, t.SafetyMargin)
 }
 
 func (t WalletConnectTokenManager) NewSessionToken(lIat, lNbf, lExp uint64, cnrID cid.ID, verb session.ContainerVerb, issuerKey keys.PublicKey) (Token, error) {